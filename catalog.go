@@ -0,0 +1,320 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+type /* error reasons */ (
+	// FailToLoadMessageCatalog is an error reason which indicates that a
+	// message catalog file cannot be read, decoded, or parsed.
+	FailToLoadMessageCatalog struct {
+		File string
+	}
+
+	// InvalidCatalogLocale is an error reason which indicates that a locale
+	// in a message catalog file is not a valid BCP 47 language tag.
+	InvalidCatalogLocale struct {
+		File   string
+		Locale string
+	}
+
+	// InvalidCatalogTemplate is an error reason which indicates that a
+	// template in a message catalog file cannot be parsed.
+	InvalidCatalogTemplate struct {
+		File   string
+		Key    string
+		Locale string
+	}
+)
+
+// CatalogDecoder is a function type which decodes the content of a message
+// catalog file into the value pointed by v.
+// json.Unmarshal is used by default, and functions like yaml.Unmarshal or
+// toml.Unmarshal can be used for the other formats.
+type CatalogDecoder func(data []byte, v interface{}) error
+
+var (
+	catalogMessages = make(map[string]map[string]*template.Template)
+	localeFallbacks = make(map[string][]language.Tag)
+)
+
+// LoadMessageCatalog is a function which loads message catalog files matched
+// with the specified pattern in the specified file system.
+// A message catalog file maps reason keys, which are joined package paths and
+// names of reason types like "github.com/foo/bar.FailToReadFile", to maps of
+// locales and text/template strings:
+//
+//	{
+//	  "github.com/foo/bar.FailToReadFile": {
+//	    "en": "could not read {{.Name}}",
+//	    "ja": "{{.Name}} を読み込めませんでした"
+//	  }
+//	}
+//
+// The file content is decoded with json.Unmarshal unless a decoder is
+// specified.
+// This function is effective only before calling FixErrCfgs function.
+func LoadMessageCatalog(
+	fsys fs.FS, pattern string, decoder ...CatalogDecoder,
+) Err {
+	decode := CatalogDecoder(json.Unmarshal)
+	if len(decoder) > 0 {
+		decode = decoder[0]
+	}
+
+	files, e := fs.Glob(fsys, pattern)
+	if e != nil {
+		return NewErr(FailToLoadMessageCatalog{File: pattern}, e)
+	}
+
+	loaded := make(map[string]map[string]*template.Template)
+
+	for _, file := range files {
+		data, e := fs.ReadFile(fsys, file)
+		if e != nil {
+			return NewErr(FailToLoadMessageCatalog{File: file}, e)
+		}
+
+		var m map[string]map[string]string
+		if e := decode(data, &m); e != nil {
+			return NewErr(FailToLoadMessageCatalog{File: file}, e)
+		}
+
+		for key, locales := range m {
+			for locale, text := range locales {
+				tag, e := language.Parse(locale)
+				if e != nil {
+					return NewErr(InvalidCatalogLocale{
+						File:   file,
+						Locale: locale,
+					}, e)
+				}
+
+				tmpl, e := template.New(key).
+					Funcs(catalogFuncs(tag)).
+					Parse(text)
+				if e != nil {
+					return NewErr(InvalidCatalogTemplate{
+						File:   file,
+						Key:    key,
+						Locale: locale,
+					}, e)
+				}
+
+				if loaded[key] == nil {
+					loaded[key] = make(map[string]*template.Template)
+				}
+				loaded[key][tag.String()] = tmpl
+			}
+		}
+	}
+
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return ok
+	}
+
+	for key, locales := range loaded {
+		if catalogMessages[key] == nil {
+			catalogMessages[key] = make(map[string]*template.Template)
+		}
+		for locale, tmpl := range locales {
+			catalogMessages[key][locale] = tmpl
+		}
+	}
+
+	return ok
+}
+
+// SetLocaleFallbacks is a function which sets locales which are searched
+// when no message for the specified locale and its parent locales is found
+// in the message catalogs.
+// The fallback locales are searched in the specified order.
+// This function is effective only before calling FixErrCfgs function.
+func SetLocaleFallbacks(lang language.Tag, fallbacks ...language.Tag) {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return
+	}
+
+	localeFallbacks[lang.String()] = fallbacks
+}
+
+// Localize method returns a human-readable text of this Err in the specified
+// locale.
+// This method searches the message catalogs for a template of the reason
+// type, in order of the specified locale, its parent locales, and their
+// fallback locales set with SetLocaleFallbacks, and renders the found
+// template with the situation of this Err.
+// If no template is found or the rendering fails, this method returns the
+// same text as Message method.
+//
+// In a template, the following functions can be used:
+//
+//	{{number .Count}}
+//	    formats a number in the locale, like "1,234.5" in English.
+//	{{plural .Count "one" "file" "other" "files"}}
+//	    selects a text by the plural form of a number in the locale. The forms
+//	    are "zero", "one", "two", "few", "many", and "other".
+func (err Err) Localize(lang language.Tag) string {
+	if err.reason == nil {
+		return ""
+	}
+
+	locales, ok := catalogMessages[err.ReasonPackage()+"."+err.ReasonName()]
+	if ok {
+		for _, tag := range localeChain(lang) {
+			tmpl, ok := locales[tag.String()]
+			if !ok {
+				continue
+			}
+			var b strings.Builder
+			if e := tmpl.Execute(&b, err.Situation()); e == nil {
+				return b.String()
+			}
+			break
+		}
+	}
+
+	return err.Message()
+}
+
+func localeChain(lang language.Tag) []language.Tag {
+	var chain []language.Tag
+	seen := make(map[string]bool)
+
+	var add func(language.Tag)
+	add = func(tag language.Tag) {
+		var fallbacks []language.Tag
+		for t := tag; ; t = t.Parent() {
+			if seen[t.String()] {
+				break
+			}
+			seen[t.String()] = true
+			if t.IsRoot() {
+				break
+			}
+			chain = append(chain, t)
+			fallbacks = append(fallbacks, localeFallbacks[t.String()]...)
+		}
+		for _, fb := range fallbacks {
+			add(fb)
+		}
+	}
+	add(lang)
+
+	return chain
+}
+
+func catalogFuncs(tag language.Tag) template.FuncMap {
+	p := message.NewPrinter(tag)
+
+	return template.FuncMap{
+		"number": func(v interface{}) string {
+			return p.Sprint(number.Decimal(v))
+		},
+		"plural": func(v interface{}, pairs ...string) (string, error) {
+			if len(pairs)%2 != 0 {
+				return "", fmt.Errorf("plural: odd number of form/text arguments")
+			}
+			n, e := pluralOperand(v)
+			if e != nil {
+				return "", e
+			}
+
+			form := pluralFormName(plural.Cardinal.MatchPlural(tag, n, 0, 0, 0, 0))
+			other := ""
+			for i := 0; i < len(pairs); i += 2 {
+				if pairs[i] == form {
+					return pairs[i+1], nil
+				}
+				if pairs[i] == "other" {
+					other = pairs[i+1]
+				}
+			}
+			return other, nil
+		},
+	}
+}
+
+// maxPluralOperand is the modulus to which a large plural operand is reduced.
+// CLDR plural rules refer to the integer digits only with modulo up to
+// 1000000 and with the comparison to zero, so the reduced operand selects the
+// same form, and fits in int even on 32-bit platforms.
+const maxPluralOperand = 1000000000
+
+func pluralOperand(v interface{}) (int, error) {
+	var u uint64
+	switch n := v.(type) {
+	case int:
+		u = absUint64(int64(n))
+	case int8:
+		u = absUint64(int64(n))
+	case int16:
+		u = absUint64(int64(n))
+	case int32:
+		u = absUint64(int64(n))
+	case int64:
+		u = absUint64(n)
+	case uint:
+		u = uint64(n)
+	case uint8:
+		u = uint64(n)
+	case uint16:
+		u = uint64(n)
+	case uint32:
+		u = uint64(n)
+	case uint64:
+		u = n
+	default:
+		return 0, fmt.Errorf("plural: unsupported operand type %T", v)
+	}
+
+	if u < maxPluralOperand {
+		return int(u), nil
+	}
+	return int(u%maxPluralOperand + maxPluralOperand), nil
+}
+
+// absUint64 returns the absolute value of n without overflow for the minimum
+// value of int64.
+func absUint64(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+func pluralFormName(form plural.Form) string {
+	switch form {
+	case plural.Zero:
+		return "zero"
+	case plural.One:
+		return "one"
+	case plural.Two:
+		return "two"
+	case plural.Few:
+		return "few"
+	case plural.Many:
+		return "many"
+	default:
+		return "other"
+	}
+}
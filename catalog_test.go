@@ -0,0 +1,241 @@
+package reasonederror_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+	"golang.org/x/text/language"
+)
+
+type /* error reasons */ (
+	FailToCopyFiles struct {
+		Count int
+		Size  int
+	}
+	FailToDeleteFiles struct {
+		Count interface{}
+	}
+)
+
+var catalogFS = fstest.MapFS{
+	"messages/file.json": &fstest.MapFile{Data: []byte(`{
+  "github.com/sttk/reasonederror_test.FailToReadFile": {
+    "en": "could not read {{.Name}}",
+    "ja": "{{.Name}} を読み込めませんでした",
+    "fr": "impossible de lire {{.Name}}"
+  },
+  "github.com/sttk/reasonederror_test.FailToCopyFiles": {
+    "en": "could not copy {{.Count}} {{plural .Count \"one\" \"file\" \"other\" \"files\"}} ({{number .Size}} bytes)",
+    "de": "{{.Count}} {{plural .Count \"one\" \"Datei\" \"other\" \"Dateien\"}} ({{number .Size}} Bytes) konnten nicht kopiert werden"
+  },
+  "github.com/sttk/reasonederror_test.FailToDeleteFiles": {
+    "en": "could not delete {{plural .Count \"one\" \"a file\" \"other\" \"files\"}}"
+  }
+}`)},
+	"messages/value.json": &fstest.MapFile{Data: []byte(`{
+  "github.com/sttk/reasonederror_test.InvalidValue": {
+    "en": "{{.Value}} is invalid for {{.Name}}",
+    "pt-BR": "{{.Value}} é inválido para {{.Name}}"
+  }
+}`)},
+	"bad/decode.json":   &fstest.MapFile{Data: []byte(`{`)},
+	"bad/locale.json":   &fstest.MapFile{Data: []byte(`{"a.B": {"!!": "x"}}`)},
+	"bad/template.json": &fstest.MapFile{Data: []byte(`{"a.B": {"en": "{{.X"}}`)},
+	"other/file.txt":    &fstest.MapFile{Data: []byte("a.B en=hello")},
+}
+
+func TestLoadMessageCatalog(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	e := re.LoadMessageCatalog(catalogFS, "messages/*.json")
+	assert.True(t, e.IsOk())
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.English), "could not read a.txt")
+	assert.Equal(t, err.Localize(language.Japanese), "a.txt を読み込めませんでした")
+	assert.Equal(t, err.Localize(language.French), "impossible de lire a.txt")
+
+	cause := re.NewErr(FailToGetValue{Name: "foo"})
+	err = re.NewErr(InvalidValue{Value: "abc"}, cause)
+	assert.Equal(t, err.Localize(language.English), "abc is invalid for foo")
+	assert.Equal(t, err.Localize(language.BrazilianPortuguese), "abc é inválido para foo")
+}
+
+func TestErr_Localize_pluralAndNumber(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.LoadMessageCatalog(catalogFS, "messages/*.json")
+
+	err := re.NewErr(FailToCopyFiles{Count: 1, Size: 1234})
+	assert.Equal(t, err.Localize(language.English), "could not copy 1 file (1,234 bytes)")
+	assert.Equal(t, err.Localize(language.German), "1 Datei (1.234 Bytes) konnten nicht kopiert werden")
+
+	err = re.NewErr(FailToCopyFiles{Count: 3, Size: 1234567})
+	assert.Equal(t, err.Localize(language.English), "could not copy 3 files (1,234,567 bytes)")
+	assert.Equal(t, err.Localize(language.German), "3 Dateien (1.234.567 Bytes) konnten nicht kopiert werden")
+}
+
+func TestErr_Localize_pluralLargeOperand(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.LoadMessageCatalog(catalogFS, "messages/*.json")
+
+	for _, n := range []interface{}{
+		1, int8(-1), int64(1), uint(1), uint64(1),
+	} {
+		err := re.NewErr(FailToDeleteFiles{Count: n})
+		assert.Equal(t, err.Localize(language.English), "could not delete a file", n)
+	}
+
+	for _, n := range []interface{}{
+		uint64(math.MaxUint64), int64(math.MinInt64), int64(math.MaxInt64),
+		uint64(1000000001), uint32(math.MaxUint32), uint(1000000000),
+	} {
+		err := re.NewErr(FailToDeleteFiles{Count: n})
+		assert.Equal(t, err.Localize(language.English), "could not delete files", n)
+	}
+}
+
+func TestErr_Localize_parentLocale(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.LoadMessageCatalog(catalogFS, "messages/*.json")
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.BritishEnglish), "could not read a.txt")
+	assert.Equal(t, err.Localize(language.MustParse("fr-CA")), "impossible de lire a.txt")
+}
+
+func TestErr_Localize_fallbacks(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.LoadMessageCatalog(catalogFS, "messages/*.json")
+	re.SetLocaleFallbacks(language.Portuguese, language.BrazilianPortuguese)
+	re.SetLocaleFallbacks(language.EuropeanPortuguese, language.Portuguese)
+	re.SetLocaleFallbacks(language.Spanish, language.French, language.English)
+	re.SetLocaleFallbacks(language.Catalan, language.Spanish)
+
+	cause := re.NewErr(FailToGetValue{Name: "foo"})
+	err := re.NewErr(InvalidValue{Value: "abc"}, cause)
+	assert.Equal(t, err.Localize(language.EuropeanPortuguese), "abc é inválido para foo")
+	assert.Equal(t, err.Localize(language.Spanish), "abc is invalid for foo")
+
+	err = re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.Catalan), "impossible de lire a.txt")
+	assert.Equal(t, err.Localize(language.MustParse("es-MX")), "impossible de lire a.txt")
+}
+
+func TestErr_Localize_noMessage(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.LoadMessageCatalog(catalogFS, "messages/*.json")
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.Korean), "{reason=FailToReadFile, Name=a.txt}")
+
+	re.SetReasonMessage(FailToReadFile{}, "cannot read {{.Name}}")
+	assert.Equal(t, err.Localize(language.Korean), "cannot read a.txt")
+
+	err = re.NewErr(FailToOpenFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.English), "could not open a.txt")
+
+	assert.Equal(t, re.Ok().Localize(language.English), "")
+}
+
+func TestLoadMessageCatalog_decoder(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	decode := func(data []byte, v interface{}) error {
+		m := v.(*map[string]map[string]string)
+		*m = make(map[string]map[string]string)
+		for _, line := range strings.Split(string(data), "\n") {
+			f := strings.SplitN(line, " ", 2)
+			kv := strings.SplitN(f[1], "=", 2)
+			(*m)["github.com/sttk/reasonederror_test."+f[0]] = map[string]string{kv[0]: kv[1]}
+		}
+		return nil
+	}
+
+	fsys := fstest.MapFS{
+		"a.txt": &fstest.MapFile{Data: []byte("FailToWriteFile en=could not write {{.Name}}")},
+	}
+	e := re.LoadMessageCatalog(fsys, "*.txt", decode)
+	assert.True(t, e.IsOk())
+
+	err := re.NewErr(FailToWriteFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.English), "could not write a.txt")
+}
+
+func TestLoadMessageCatalog_errors(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	e := re.LoadMessageCatalog(catalogFS, "[")
+	switch r := e.Reason().(type) {
+	case re.FailToLoadMessageCatalog:
+		assert.Equal(t, r.File, "[")
+	default:
+		assert.Fail(t, e.Error())
+	}
+
+	e = re.LoadMessageCatalog(catalogFS, "bad/decode.json")
+	switch r := e.Reason().(type) {
+	case re.FailToLoadMessageCatalog:
+		assert.Equal(t, r.File, "bad/decode.json")
+	default:
+		assert.Fail(t, e.Error())
+	}
+
+	e = re.LoadMessageCatalog(catalogFS, "bad/locale.json")
+	switch r := e.Reason().(type) {
+	case re.InvalidCatalogLocale:
+		assert.Equal(t, r.File, "bad/locale.json")
+		assert.Equal(t, r.Locale, "!!")
+	default:
+		assert.Fail(t, e.Error())
+	}
+
+	e = re.LoadMessageCatalog(catalogFS, "bad/template.json")
+	switch r := e.Reason().(type) {
+	case re.InvalidCatalogTemplate:
+		assert.Equal(t, r.File, "bad/template.json")
+		assert.Equal(t, r.Key, "a.B")
+		assert.Equal(t, r.Locale, "en")
+	default:
+		assert.Fail(t, e.Error())
+	}
+
+	decode := func(data []byte, v interface{}) error {
+		return errors.New("bad")
+	}
+	e = re.LoadMessageCatalog(catalogFS, "messages/*.json", decode)
+	assert.True(t, e.IsNotOk())
+	assert.Equal(t, e.Cause().Error(), "bad")
+}
+
+func TestLoadMessageCatalog_afterFixErrCfgs(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.FixErrCfgs()
+
+	e := re.LoadMessageCatalog(catalogFS, "messages/*.json")
+	assert.True(t, e.IsOk())
+
+	re.SetLocaleFallbacks(language.Spanish, language.English)
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Localize(language.English), "{reason=FailToReadFile, Name=a.txt}")
+}
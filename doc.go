@@ -80,6 +80,173 @@ Therefore the reason can evaluate by using type switch.
 	    ...
 	}
 
+# Chaining
+
+Err has methods to chain processes without repeating checks of IsNotOk:
+IfOk executes a function if no error, IfNotOk and OrElse execute a function
+to recover from an error, and Finally executes a function in both cases.
+OnReason function executes a function only for a specific reason type.
+
+	err := step1().
+	    IfOk(step2).
+	    IfNotOk(func(e reasonederror.Err) reasonederror.Err {
+	        return reasonederror.OnReason(e, func(r FailToGetValue) reasonederror.Err {
+	            return reasonederror.Ok() // ignores this reason
+	        })
+	    }).
+	    Finally(cleanup)
+
+For functions which return values, Result holds a value and an Err, and Map
+and AndThen functions chain them.
+
+	r := reasonederror.AndThen(reasonederror.AndThen(parse(s), validate), save)
+	v, err := r.Unwrap()
+
+# Concurrent tasks
+
+Group runs tasks concurrently and aggregates their Err(s).
+
+	g := reasonederror.NewGroup(ctx, reasonederror.GroupOptions{Limit: 4})
+	for _, url := range urls {
+	    url := url
+	    g.Go(url, func(ctx context.Context) reasonederror.Err {
+	        return fetch(ctx, url)
+	    })
+	}
+	err := g.Wait()
+
+By default, the context passed to tasks is canceled when a task fails, and if
+CollectAll option is true, all tasks are run to the end.
+Panics in tasks are recovered as failures.
+Wait method returns an Err of which reason is TasksFailed and cause is an Errs,
+which contains an Err of reason TaskFailed with the task name for each failed
+task.
+A task which was not run because the context was done is also regarded as a
+failure with a cause of reason TaskCanceled.
+
+# Human-readable message
+
+Error method of Err returns a text which lists the reason name and its
+fields, which is suitable for logs but not for end users.
+Message method of Err returns a human-readable text instead.
+If a reason struct has Message method, its result is used.
+Otherwise, a text/template string registered with SetReasonMessage is
+rendered with the situation of the Err.
+
+	reasonederror.SetReasonMessage(FailToReadFile{}, "could not read {{.Name}}")
+	reasonederror.FixErrCfgs()
+
+	err := reasonederror.NewErr(FailToReadFile{Name: "a.txt"})
+	err.Message()  // => "could not read a.txt"
+
+If neither is available, Message method returns the same text as Error method.
+
+RedactedMessage method of Err returns a text which can be shown outside of an
+application.
+It renders the template with the redacted situation, and returns the reason
+name instead of the result of Message method of a reason struct which has
+redacted fields.
+If neither is available, it returns an empty string.
+
+# Localized message
+
+Localize method of Err returns a human-readable text in a specified locale.
+The texts are given as message catalog files, which map reason keys, joined
+package paths and names of reason types, to text/template strings for each
+locale.
+
+	{
+	  "github.com/foo/bar.FailToCopyFiles": {
+	    "en": "could not copy {{.Count}} {{plural .Count \"one\" \"file\" \"other\" \"files\"}}",
+	    "ja": "{{number .Count}} 個のファイルをコピーできませんでした"
+	  }
+	}
+
+These files are loaded with LoadMessageCatalog function, typically from an
+embedded file system.
+
+	//go:embed messages/*.json
+	var messages embed.FS
+
+	reasonederror.LoadMessageCatalog(messages, "messages/*.json")
+	reasonederror.SetLocaleFallbacks(language.Catalan, language.Spanish)
+	reasonederror.FixErrCfgs()
+
+	err.Localize(language.Japanese)
+
+If no text for the locale is found, Localize method searches its parent
+locales (e.g. "en" for "en-GB") and the fallback locales set with
+SetLocaleFallbacks function, and finally returns the result of Message method.
+
+# Severity and classification
+
+A reason type can declare how bad an error is and what kind of error it is,
+by having Severity and Class methods or by being registered with
+SetReasonAttrs function.
+
+	reasonederror.SetReasonAttrs(InvalidInput{}, reasonederror.ReasonAttrs{
+	    Severity: reasonederror.SeverityWarn,
+	    Class:    reasonederror.ClassUserError,
+	})
+
+These attributes are obtained with Severity, IsUserError, IsSystemError,
+IsTransient, and IsSecurityRelevant methods of Err.
+A reason which declares nothing has SeverityError and no classification flag.
+
+# Fingerprint
+
+Fingerprint method of Err returns a hash string for grouping the same errors,
+which is built from the reason types in the cause chain and the values of
+reason struct fields tagged with `fingerprint:"true"`.
+
+	type FailToConnect struct {
+	    Host      string `fingerprint:"true"`
+	    RequestID string
+	}
+
+	key := err.Fingerprint()     // the same for any RequestID
+	key := err.Fingerprint(occ)  // also distinguishes the file and line
+
+# Retrying
+
+A reason type which has ClassRetryable flag indicates that an operation which
+caused the error is worth retrying.
+Retry function executes a function repeatedly while it returns such an Err,
+waiting with exponential backoff and jitter, or with a hint in the situation
+field named RetryAfter.
+
+	err := reasonederror.Retry(ctx, reasonederror.RetryPolicy{
+	    MaxAttempts:     5,
+	    InitialInterval: 200 * time.Millisecond,
+	    Jitter:          0.2,
+	}, func() reasonederror.Err {
+	    return callRemoteService()
+	})
+
+If the function fails in all attempts, Retry returns an Err of which reason is
+RetriesExhausted and cause is the Err of the last attempt.
+
+# Panic recovery
+
+Recover function recovers a panic and converts it into an Err of which reason
+is Panicked, and Try function executes a function with it.
+
+	func doSomething() (err reasonederror.Err) {
+	    defer reasonederror.Recover(&err)
+	    ...
+	}
+
+	err := reasonederror.Try(func() reasonederror.Err {
+	    ...
+	})
+
+Panicked has the panic value and the stack trace, and if the panic value is an
+error, it becomes the cause.
+This Err is notified to handlers like one created with NewErr function, and the
+ErrOccasion points to the position where the panic occurred.
+NewErrAtPanic function creates an Err with another reason in the same way, and
+is used in a deferred function which recovers a panic by itself.
+
 # Error notification
 
 By registering handlers with AddSyncErrHandler or AddAsyncErrHandler, these
@@ -104,5 +271,59 @@ Whenever an Err is created with NewErr function, these registered
 handlers are called.
 The (1) handler is executed synchronously, and (2) is executed asynchronously
 in another goroutine.
+
+A handler can be limited to Err(s) of which severities are equal to or higher
+than a specified severity with FilterBySeverity function.
+
+	reasonederror.AddAsyncErrHandler(reasonederror.FilterBySeverity(
+	    reasonederror.SeverityWarn,
+	    func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	        // (3)
+	    },
+	))
+
+# Occasion attributes
+
+Attributes common to all notifications, like a service name and a version,
+can be attached to ErrOccasion(s) with SetErrOccasionAttrs function, and
+attributes computed for each notification can be attached by enrichers added
+with AddErrOccasionEnricher function.
+Enrichers are executed once for each Err before the handlers, and these
+functions are effective only before calling FixErrCfgs function.
+
+	reasonederror.SetErrOccasionAttrs(map[string]interface{}{
+	    "service": "api",
+	    "version": version,
+	})
+	reasonederror.AddErrOccasionEnricher(func(occ *reasonederror.ErrOccasion) {
+	    occ.SetAttr("goroutines", runtime.NumGoroutine())
+	})
+	reasonederror.FixErrCfgs()
+
+The attributes are obtained with Attrs method of ErrOccasion in handlers.
+
+# Handler middleware
+
+A handler can also be a Handler, which is an interface with Handle method and
+is registered with AddSyncHandler or AddAsyncHandler function.
+Middleware(s), which wrap a Handler, can be stacked with Chain function and
+shared by several Handler(s).
+Filter, Sample, Enrich, Redact and RecoverPanic functions create
+Middleware(s).
+Enrich Middleware attaches attributes to ErrOccasion(s) only for the following
+Handler(s), while enrichers added with AddErrOccasionEnricher function do it
+for all handlers.
+
+	chain := reasonederror.Chain(
+	    reasonederror.RecoverPanic(nil),
+	    reasonederror.Sample(0.1),
+	    reasonederror.Redact(),
+	)
+	reasonederror.AddAsyncHandler(chain.Then(hook))
+	reasonederror.AddAsyncHandler(chain.Then(sink))
+	reasonederror.FixErrCfgs()
+	defer reasonederror.CloseErrHandlers()
+
+If a Handler has Close method, CloseErrHandlers function calls it.
 */
 package reasonederror
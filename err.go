@@ -55,26 +55,42 @@ func (err Err) Reason() interface{} {
 
 // ReasonName method returns a name of a reason struct type.
 func (err Err) ReasonName() string {
-	if err.reason == nil {
+	t := reasonType(err.reason)
+	if t == nil {
 		return ""
 	}
-	t := reflect.TypeOf(err.reason)
-	if t.Kind() == reflect.Ptr {
-		t = t.Elem()
-	}
 	return t.Name()
 }
 
 // ReasonPackage method returns a package path of a reason struct type.
 func (err Err) ReasonPackage() string {
-	if err.reason == nil {
+	t := reasonType(err.reason)
+	if t == nil {
 		return ""
 	}
-	t := reflect.TypeOf(err.reason)
+	return t.PkgPath()
+}
+
+// ReasonType is a function which returns the struct type of the specified
+// reason.
+// If the reason is a pointer, this function returns the type it points to,
+// so this is useful as a key of a registry for reason types, and if the
+// reason is nil, this function returns nil.
+func ReasonType(reason interface{}) reflect.Type {
+	return reasonType(reason)
+}
+
+// reasonType returns the struct type of a reason.
+// If the reason is a pointer, this function returns the type it points to.
+func reasonType(reason interface{}) reflect.Type {
+	if reason == nil {
+		return nil
+	}
+	t := reflect.TypeOf(reason)
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
-	return t.PkgPath()
+	return t
 }
 
 // Cause method returns a causal error of this Err.
@@ -205,3 +221,51 @@ func (err Err) IfOk(fn func() Err) Err {
 	}
 	return err
 }
+
+// IfNotOk method executes an argument function with this Err if this Err
+// indicates some error, and returns the result of the function.
+// This method is used to recover from an error.
+// If this Err indicates no error, this method just returns this Err.
+func (err Err) IfNotOk(fn func(Err) Err) Err {
+	if err.IsNotOk() {
+		return fn(err)
+	}
+	return err
+}
+
+// OrElse method executes an argument function if this Err indicates some
+// error, and returns the result of the function instead of this Err.
+// If this Err indicates no error, this method just returns this Err.
+func (err Err) OrElse(fn func() Err) Err {
+	if err.IsNotOk() {
+		return fn()
+	}
+	return err
+}
+
+// Finally method executes an argument function regardless of whether this
+// Err indicates an error or not, and returns this Err.
+func (err Err) Finally(fn func()) Err {
+	fn()
+	return err
+}
+
+// OnReason is a function which executes an argument function with the reason
+// of an Err if the reason is of the type T or a pointer to it, and returns the
+// result of the function.
+// Otherwise, this function just returns the Err.
+//
+//	err = reasonederror.OnReason(err, func(r FailToGetValue) reasonederror.Err {
+//	    return reasonederror.Ok() // ignores this reason
+//	})
+func OnReason[T any](err Err, fn func(T) Err) Err {
+	switch r := err.reason.(type) {
+	case T:
+		return fn(r)
+	case *T:
+		if r != nil {
+			return fn(*r)
+		}
+	}
+	return err
+}
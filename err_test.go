@@ -2,6 +2,7 @@ package reasonederror_test
 
 import (
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -249,3 +250,110 @@ func TestErr_IfOk_err(t *testing.T) {
 		assert.Fail(t, err2.Error())
 	}
 }
+
+func TestErr_IfNotOk_ok(t *testing.T) {
+	err := re.Ok()
+
+	s := ""
+	err2 := err.IfNotOk(func(e re.Err) re.Err {
+		s = "executed."
+		return re.NewErr(InvalidValue{Value: "x"})
+	})
+
+	assert.Equal(t, s, "")
+	assert.True(t, err2.IsOk())
+}
+
+func TestErr_IfNotOk_err(t *testing.T) {
+	err := re.NewErr(InvalidValue{Value: "x"})
+
+	var got re.Err
+	err2 := err.IfNotOk(func(e re.Err) re.Err {
+		got = e
+		return re.Ok()
+	})
+
+	assert.Equal(t, got.Get("Value"), "x")
+	assert.True(t, err.IsNotOk())
+	assert.True(t, err2.IsOk())
+}
+
+func TestErr_OrElse_ok(t *testing.T) {
+	err := re.Ok()
+
+	s := ""
+	err2 := err.OrElse(func() re.Err {
+		s = "executed."
+		return re.NewErr(InvalidValue{Value: "x"})
+	})
+
+	assert.Equal(t, s, "")
+	assert.True(t, err2.IsOk())
+}
+
+func TestErr_OrElse_err(t *testing.T) {
+	err := re.NewErr(InvalidValue{Value: "x"})
+
+	err2 := err.OrElse(func() re.Err {
+		return re.NewErr(FailToGetValue{Name: "y"})
+	})
+
+	assert.True(t, err.IsNotOk())
+	switch err2.Reason().(type) {
+	case FailToGetValue:
+	default:
+		assert.Fail(t, err2.Error())
+	}
+}
+
+func TestErr_Finally(t *testing.T) {
+	n := 0
+
+	err := re.Ok().Finally(func() { n++ })
+	assert.Equal(t, n, 1)
+	assert.True(t, err.IsOk())
+
+	err = re.NewErr(InvalidValue{Value: "x"}).Finally(func() { n++ })
+	assert.Equal(t, n, 2)
+	assert.Equal(t, err.ReasonName(), "InvalidValue")
+}
+
+func TestReasonType(t *testing.T) {
+	assert.Equal(t, re.ReasonType(InvalidValue{}), reflect.TypeOf(InvalidValue{}))
+	assert.Equal(t, re.ReasonType(&InvalidValue{}), reflect.TypeOf(InvalidValue{}))
+	assert.Nil(t, re.ReasonType(nil))
+}
+
+func TestOnReason(t *testing.T) {
+	var got InvalidValue
+	handle := func(r InvalidValue) re.Err {
+		got = r
+		return re.Ok()
+	}
+
+	err := re.OnReason(re.NewErr(InvalidValue{Value: "a"}), handle)
+	assert.True(t, err.IsOk())
+	assert.Equal(t, got.Value, "a")
+
+	err = re.OnReason(re.NewErr(&InvalidValue{Value: "b"}), handle)
+	assert.True(t, err.IsOk())
+	assert.Equal(t, got.Value, "b")
+
+	err = re.OnReason(re.NewErr(FailToGetValue{Name: "c"}), handle)
+	assert.Equal(t, err.ReasonName(), "FailToGetValue")
+	assert.Equal(t, got.Value, "b")
+
+	var nilReason *InvalidValue
+	err = re.OnReason(re.NewErr(nilReason), handle)
+	assert.True(t, err.IsNotOk())
+	assert.Equal(t, got.Value, "b")
+
+	err = re.OnReason(re.Ok(), handle)
+	assert.True(t, err.IsOk())
+	assert.Equal(t, got.Value, "b")
+
+	err = re.OnReason(re.NewErr(&InvalidValue{Value: "d"}), func(r *InvalidValue) re.Err {
+		return re.NewErr(FailToGetValue{Name: r.Value})
+	})
+	assert.Equal(t, err.Get("Name"), "d")
+}
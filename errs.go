@@ -0,0 +1,62 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errs is a slice of Err(s) which represents multiple errors.
+// Errs is used as a cause of an Err which aggregates multiple errors, and
+// errors.Is and errors.As functions search all of the Err(s).
+// Because these functions walk Unwrap() []error method only since Go 1.20,
+// Errs also has Is and As methods for older versions.
+type Errs []Err
+
+// Error method returns a string which expresses these errors.
+func (errs Errs) Error() string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, err := range errs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(err.Error())
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// Unwrap method returns the Err(s) in this Errs as errors.
+func (errs Errs) Unwrap() []error {
+	a := make([]error, len(errs))
+	for i, err := range errs {
+		a[i] = err
+	}
+	return a
+}
+
+// Is method reports whether any of the Err(s) in this Errs matches the
+// specified error with errors.Is function.
+func (errs Errs) Is(target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As method finds the first of the Err(s) in this Errs which matches the
+// specified target with errors.As function.
+func (errs Errs) As(target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
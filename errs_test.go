@@ -0,0 +1,63 @@
+package reasonederror_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+func TestErrs_Error(t *testing.T) {
+	errs := re.Errs{
+		re.NewErr(InvalidValue{Value: "a"}),
+		re.NewErr(FailToGetValue{Name: "b"}),
+	}
+	assert.Equal(t, errs.Error(),
+		"[{reason=InvalidValue, Value=a}, {reason=FailToGetValue, Name=b}]")
+
+	assert.Equal(t, re.Errs{}.Error(), "[]")
+}
+
+func TestErrs_Unwrap(t *testing.T) {
+	cause := errors.New("def")
+	errs := re.Errs{
+		re.NewErr(InvalidValue{Value: "a"}),
+		re.NewErr(FailToGetValue{Name: "b"}, cause),
+	}
+
+	a := errs.Unwrap()
+	assert.Equal(t, len(a), 2)
+	assert.Equal(t, a[0], errs[0])
+	assert.Equal(t, a[1], errs[1])
+
+	err := re.NewErr(InvalidValue{Value: "c"}, errs)
+	assert.True(t, errors.Is(err, cause))
+
+	var e re.Err
+	assert.True(t, errors.As(errs, &e))
+	assert.Equal(t, e.ReasonName(), "InvalidValue")
+}
+
+func TestErrs_IsAndAs(t *testing.T) {
+	cause := errors.New("def")
+	errs := re.Errs{
+		re.NewErr(InvalidValue{Value: "a"}),
+		re.NewErr(FailToGetValue{Name: "b"}, cause),
+	}
+
+	assert.True(t, errs.Is(cause))
+	assert.False(t, errs.Is(errors.New("def")))
+
+	var e re.Err
+	assert.True(t, errs.As(&e))
+	assert.Equal(t, e.ReasonName(), "InvalidValue")
+
+	var pe *testError
+	assert.False(t, errs.As(&pe))
+	assert.False(t, re.Errs{}.Is(cause))
+}
+
+type testError struct{}
+
+func (e *testError) Error() string { return "test" }
@@ -226,3 +226,41 @@ func ExampleErr_IfOk() {
 	// Output:
 	// execute if non error.
 }
+
+func ExampleErr_IfNotOk() {
+	type FailToDoSomething struct{}
+
+	err := reasonederror.NewErr(FailToDoSomething{})
+	err = err.IfNotOk(func(e reasonederror.Err) reasonederror.Err {
+		fmt.Println("recover from " + e.ReasonName())
+		return reasonederror.Ok()
+	})
+	fmt.Printf("%v\n", err.IsOk())
+
+	// Output:
+	// recover from FailToDoSomething
+	// true
+}
+
+func ExampleOnReason() {
+	type (
+		FailToDoSomething struct{ Name string }
+		FailToDoAnother   struct{}
+	)
+
+	handle := func(r FailToDoSomething) reasonederror.Err {
+		fmt.Println("handle FailToDoSomething: " + r.Name)
+		return reasonederror.Ok()
+	}
+
+	err := reasonederror.OnReason(reasonederror.NewErr(FailToDoSomething{Name: "abc"}), handle)
+	fmt.Printf("%v\n", err.IsOk())
+
+	err = reasonederror.OnReason(reasonederror.NewErr(FailToDoAnother{}), handle)
+	fmt.Printf("%v\n", err.IsOk())
+
+	// Output:
+	// handle FailToDoSomething: abc
+	// true
+	// false
+}
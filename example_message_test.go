@@ -0,0 +1,22 @@
+package reasonederror_test
+
+import (
+	"fmt"
+
+	"github.com/sttk/reasonederror"
+)
+
+func ExampleSetReasonMessage() {
+	type FailToReadFile struct{ Name string }
+
+	reasonederror.SetReasonMessage(FailToReadFile{}, "could not read {{.Name}}")
+	reasonederror.FixErrCfgs()
+
+	err := reasonederror.NewErr(FailToReadFile{Name: "config.json"})
+	fmt.Println(err.Message())
+
+	// Output:
+	// could not read config.json
+
+	reasonederror.ClearErrHandlers()
+}
@@ -0,0 +1,43 @@
+package reasonederror_test
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sttk/reasonederror"
+)
+
+func ExampleAndThen() {
+	type (
+		NotANumber  struct{ Text string }
+		OutOfRange  struct{ Value int }
+		FailToStore struct{ Value int }
+	)
+
+	parse := func(s string) reasonederror.Result[int] {
+		n, e := strconv.Atoi(s)
+		if e != nil {
+			return reasonederror.ErrResult[int](reasonederror.NewErr(NotANumber{Text: s}))
+		}
+		return reasonederror.OkResult(n)
+	}
+	validate := func(n int) reasonederror.Result[int] {
+		if n < 0 || n > 100 {
+			return reasonederror.ErrResult[int](reasonederror.NewErr(OutOfRange{Value: n}))
+		}
+		return reasonederror.OkResult(n)
+	}
+	store := func(n int) reasonederror.Result[string] {
+		return reasonederror.OkResult("stored " + strconv.Itoa(n))
+	}
+
+	for _, s := range []string{"42", "abc", "420"} {
+		r := reasonederror.AndThen(reasonederror.AndThen(parse(s), validate), store)
+		fmt.Println(r.UnwrapOr(r.Err().Error()))
+	}
+
+	// Output:
+	// stored 42
+	// {reason=NotANumber, Text=abc}
+	// {reason=OutOfRange, Value=420}
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package expvarerr provides a function to publish the counts of Err
+// notifications per reason with expvar package, which are shown in
+// /debug/vars.
+//
+// This is a separate package from reasonederror, because importing expvar
+// package registers /debug/vars on http.DefaultServeMux.
+//
+//	expvarerr.PublishExpvar("errs")
+//	reasonederror.FixErrCfgs()
+//
+// The published variable is a map from "<package>.<reason>" to a map which
+// has the following entries:
+//
+//   - count: the number of notifications,
+//   - last_seen: the time of the last notification in RFC 3339 format,
+//   - last_occasion: the file and line of the last notification.
+package expvarerr
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// AlreadyPublished is an error reason which indicates that an expvar
+	// variable with the same name is already published.
+	AlreadyPublished struct {
+		Name string
+	}
+
+	// AlreadyFixed is an error reason which indicates that
+	// reasonederror.FixErrCfgs function has been called already, so a handler
+	// cannot be registered.
+	AlreadyFixed struct {
+		Name string
+	}
+)
+
+var publishMutex sync.Mutex
+
+// PublishExpvar is a function which publishes an expvar.Map of the specified
+// name, and registers a synchronous Err notification handler which updates it.
+// This function must be called before calling reasonederror.FixErrCfgs
+// function, otherwise this function publishes nothing and returns an Err of
+// which reason is AlreadyFixed.
+// If a variable of the name is already published, this function returns an
+// Err of which reason is AlreadyPublished, whether FixErrCfgs function has
+// been called or not.
+func PublishExpvar(name string) reasonederror.Err {
+	publishMutex.Lock()
+	defer publishMutex.Unlock()
+
+	if expvar.Get(name) != nil {
+		return reasonederror.NewErr(AlreadyPublished{Name: name})
+	}
+
+	if reasonederror.IsErrCfgsFixed() {
+		return reasonederror.NewErr(AlreadyFixed{Name: name})
+	}
+
+	c := &counter{vars: new(expvar.Map).Init()}
+	expvar.Publish(name, c.vars)
+
+	reasonederror.AddSyncErrHandler(c.handle)
+	return reasonederror.Ok()
+}
+
+type reasonVars struct {
+	count        expvar.Int
+	lastSeen     expvar.String
+	lastOccasion expvar.String
+}
+
+type counter struct {
+	vars    *expvar.Map
+	mutex   sync.Mutex
+	reasons map[string]*reasonVars
+}
+
+func (c *counter) handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	key := err.ReasonPackage() + "." + err.ReasonName()
+
+	c.mutex.Lock()
+	rv, ok := c.reasons[key]
+	if !ok {
+		rv = &reasonVars{}
+		m := new(expvar.Map).Init()
+		m.Set("count", &rv.count)
+		m.Set("last_seen", &rv.lastSeen)
+		m.Set("last_occasion", &rv.lastOccasion)
+		c.vars.Set(key, m)
+
+		if c.reasons == nil {
+			c.reasons = make(map[string]*reasonVars)
+		}
+		c.reasons[key] = rv
+	}
+	rv.count.Add(1)
+	rv.lastSeen.Set(occ.Time().Format(time.RFC3339Nano))
+	rv.lastOccasion.Set(occ.File() + ":" + strconv.Itoa(occ.Line()))
+	c.mutex.Unlock()
+}
@@ -0,0 +1,101 @@
+package expvarerr_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/expvarerr"
+)
+
+type /* error reasons */ (
+	FailToConnect struct {
+		Host string
+	}
+	RequestTimeout struct{}
+)
+
+func TestMain(m *testing.M) {
+	if err := expvarerr.PublishExpvar("errs"); err.IsNotOk() {
+		panic(err)
+	}
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+type reasonVars struct {
+	Count        int    `json:"count"`
+	LastSeen     string `json:"last_seen"`
+	LastOccasion string `json:"last_occasion"`
+}
+
+func getVars(t *testing.T) map[string]reasonVars {
+	var m map[string]reasonVars
+	assert.Nil(t, json.Unmarshal([]byte(expvar.Get("errs").String()), &m))
+	return m
+}
+
+func TestPublishExpvar(t *testing.T) {
+	prev := getVars(t)
+	before := time.Now()
+
+	reasonederror.NewErr(FailToConnect{Host: "a"})
+	reasonederror.NewErr(RequestTimeout{})
+	_, _, line, _ := runtime.Caller(0)
+	reasonederror.NewErr(FailToConnect{Host: "b"})
+
+	m := getVars(t)
+
+	key := "github.com/sttk/reasonederror/expvarerr_test.FailToConnect"
+	v := m[key]
+	assert.Equal(t, v.Count-prev[key].Count, 2)
+	assert.Equal(t, v.LastOccasion, "expvarerr_test.go:"+strconv.Itoa(line+1))
+	lastSeen, e := time.Parse(time.RFC3339Nano, v.LastSeen)
+	assert.Nil(t, e)
+	assert.False(t, lastSeen.Before(before))
+
+	key = "github.com/sttk/reasonederror/expvarerr_test.RequestTimeout"
+	v = m[key]
+	assert.Equal(t, v.Count-prev[key].Count, 1)
+	assert.Equal(t, v.LastOccasion, "expvarerr_test.go:"+strconv.Itoa(line-1))
+}
+
+func TestPublishExpvar_debugVars(t *testing.T) {
+	reasonederror.NewErr(RequestTimeout{})
+
+	rec := httptest.NewRecorder()
+	expvar.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/vars", nil))
+
+	var m map[string]json.RawMessage
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &m))
+	assert.Contains(t, string(m["errs"]), `"github.com/sttk/reasonederror/expvarerr_test.RequestTimeout"`)
+}
+
+func TestPublishExpvar_alreadyPublished(t *testing.T) {
+	err := expvarerr.PublishExpvar("errs")
+	switch r := err.Reason().(type) {
+	case expvarerr.AlreadyPublished:
+		assert.Equal(t, r.Name, "errs")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestPublishExpvar_alreadyFixed(t *testing.T) {
+	err := expvarerr.PublishExpvar("errs_after_fix")
+	switch r := err.Reason().(type) {
+	case expvarerr.AlreadyFixed:
+		assert.Equal(t, r.Name, "errs_after_fix")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Nil(t, expvar.Get("errs_after_fix"))
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"reflect"
+	"strconv"
+)
+
+// Fingerprint method returns a hash string which identifies the kind of this
+// Err, for grouping the same errors.
+//
+// The hash is the hex-encoded SHA-256 of the package path and the name of the
+// reason type, the values of the reason struct fields tagged with
+// `fingerprint:"true"`, and the same of the Err(s) in the cause chain.
+// For a cause which is not an Err, its type is used.
+// If an ErrOccasion is specified, its file and line are also used.
+//
+//	type FailToConnect struct {
+//	    Host      string `fingerprint:"true"`
+//	    RequestID string
+//	}
+//
+// The hash does not depend on the process or the Go version, but the values of
+// tagged fields should be of types of which formats with fmt.Sprint are
+// stable, like strings and numbers.
+// If this Err is Ok, this method returns an empty string.
+func (err Err) Fingerprint(occ ...ErrOccasion) string {
+	if err.IsOk() {
+		return ""
+	}
+
+	h := sha256.New()
+	writeFingerprint(h, err)
+
+	for _, o := range occ {
+		writeFingerprintField(h, "occasion", o.file+":"+strconv.Itoa(o.line))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func writeFingerprint(h hash.Hash, e error) {
+	for e != nil {
+		switch err := e.(type) {
+		case Err:
+			if err.IsOk() {
+				return
+			}
+			writeFingerprintReason(h, err.reason)
+			e = err.cause
+		case Errs:
+			writeFingerprintField(h, "errs", strconv.Itoa(len(err)))
+			for _, x := range err {
+				writeFingerprint(h, x)
+			}
+			return
+		default:
+			writeFingerprintField(h, "cause", typeName(reflect.TypeOf(e)))
+			e = errors.Unwrap(e)
+		}
+	}
+}
+
+func writeFingerprintReason(h hash.Hash, reason interface{}) {
+	v := reflect.ValueOf(reason)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	t := v.Type()
+	writeFingerprintField(h, "reason", typeName(t))
+
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	n := v.NumField()
+	for i := 0; i < n; i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get("fingerprint") != "true" {
+			continue
+		}
+		f := v.Field(i)
+		if !f.CanInterface() {
+			continue
+		}
+		writeFingerprintField(h, "field", sf.Name+"="+fmt.Sprint(f.Interface()))
+	}
+}
+
+// writeFingerprintField writes a labeled and length-prefixed value, so that
+// different sequences of values never produce the same input of the hash.
+func writeFingerprintField(h hash.Hash, label, value string) {
+	h.Write([]byte(label + ":" + strconv.Itoa(len(value)) + ":" + value + ";"))
+}
+
+func typeName(t reflect.Type) string {
+	ptr := ""
+	for t.Kind() == reflect.Ptr {
+		ptr += "*"
+		t = t.Elem()
+	}
+	if t.Name() == "" {
+		return ptr + t.String()
+	}
+	return ptr + t.PkgPath() + "." + t.Name()
+}
@@ -0,0 +1,90 @@
+package reasonederror_test
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	FailToConnect struct {
+		Host      string `fingerprint:"true"`
+		Port      int    `fingerprint:"true"`
+		RequestID string
+	}
+)
+
+func TestErr_Fingerprint(t *testing.T) {
+	err := re.NewErr(FailToConnect{Host: "db", Port: 5432, RequestID: "r1"})
+	fp := err.Fingerprint()
+
+	assert.Equal(t, len(fp), 64)
+	assert.Equal(t, fp, "e9369b7b199932c3a5f3fb2c7fbd5e67a31505ca6bb55428021cd61fd57bcd77")
+
+	err2 := re.NewErr(FailToConnect{Host: "db", Port: 5432, RequestID: "r2"})
+	assert.Equal(t, err2.Fingerprint(), fp)
+
+	err3 := re.NewErr(&FailToConnect{Host: "db", Port: 5432, RequestID: "r3"})
+	assert.Equal(t, err3.Fingerprint(), fp)
+
+	err4 := re.NewErr(FailToConnect{Host: "cache", Port: 5432, RequestID: "r1"})
+	assert.NotEqual(t, err4.Fingerprint(), fp)
+
+	err5 := re.NewErr(FailToGetValue{Name: "db"})
+	assert.NotEqual(t, err5.Fingerprint(), fp)
+}
+
+func TestErr_Fingerprint_causeChain(t *testing.T) {
+	newErr := func(cause error) re.Err {
+		return re.NewErr(FailToConnect{Host: "db"}, cause)
+	}
+
+	fp := newErr(nil).Fingerprint()
+
+	fp1 := newErr(re.NewErr(InvalidValue{Value: "a"})).Fingerprint()
+	assert.NotEqual(t, fp1, fp)
+	assert.Equal(t, newErr(re.NewErr(InvalidValue{Value: "b"})).Fingerprint(), fp1)
+
+	fp2 := newErr(&fs.PathError{Op: "open", Path: "a", Err: fs.ErrNotExist}).Fingerprint()
+	assert.NotEqual(t, fp2, fp)
+	assert.NotEqual(t, fp2, fp1)
+	assert.Equal(t, newErr(&fs.PathError{Op: "read", Path: "b", Err: fs.ErrNotExist}).Fingerprint(), fp2)
+	assert.NotEqual(t, newErr(&fs.PathError{Op: "open", Path: "a", Err: InvalidValueError{}}).Fingerprint(), fp2)
+
+	fp3 := newErr(fmt.Errorf("wrapped: %w", re.NewErr(InvalidValue{}))).Fingerprint()
+	assert.NotEqual(t, fp3, fp1)
+
+	errs := re.Errs{re.NewErr(InvalidValue{}), re.NewErr(FailToGetValue{})}
+	fp4 := newErr(errs).Fingerprint()
+	assert.NotEqual(t, fp4, fp1)
+	assert.NotEqual(t, newErr(errs[:1]).Fingerprint(), fp4)
+}
+
+func TestErr_Fingerprint_occasion(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	var occs []re.ErrOccasion
+	re.AddSyncErrHandler(func(err re.Err, occ re.ErrOccasion) {
+		occs = append(occs, occ)
+	})
+	re.FixErrCfgs()
+
+	var errs []re.Err
+	for i := 0; i < 2; i++ {
+		errs = append(errs, re.NewErr(FailToConnect{Host: "db"}))
+	}
+	errs = append(errs, re.NewErr(FailToConnect{Host: "db"}))
+
+	fp0 := errs[0].Fingerprint(occs[0])
+	assert.NotEqual(t, fp0, errs[0].Fingerprint())
+	assert.Equal(t, errs[1].Fingerprint(occs[1]), fp0)
+	assert.NotEqual(t, errs[2].Fingerprint(occs[2]), fp0)
+}
+
+func TestErr_Fingerprint_ok(t *testing.T) {
+	assert.Equal(t, re.Ok().Fingerprint(), "")
+}
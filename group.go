@@ -0,0 +1,153 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+type /* error reasons */ (
+	// TasksFailed is an error reason which indicates that some of the tasks in
+	// a Group failed.
+	// The cause is an Errs which contains the Err(s) of the failed tasks, each
+	// of which has the reason TaskFailed.
+	TasksFailed struct {
+		Failed int
+		Total  int
+	}
+
+	// TaskFailed is an error reason which indicates that a task in a Group
+	// failed.
+	// The cause is the Err returned by the task, or an Err of which reason is
+	// TaskCanceled if the task was not run.
+	TaskFailed struct {
+		Task string
+	}
+
+	// TaskCanceled is an error reason which indicates that a task in a Group
+	// was not run because the context was done before the task started.
+	// The cause is the error of the context.
+	TaskCanceled struct{}
+)
+
+// GroupOptions is a struct which configures the behavior of a Group.
+type GroupOptions struct {
+	// Limit is the maximum number of tasks which run concurrently.
+	// If this is zero or less, the number is not limited.
+	Limit int
+
+	// CollectAll specifies whether to run all tasks even if some of them fail.
+	// If this is false, the context passed to tasks is canceled when a task
+	// fails, and the tasks which have not started yet are skipped and
+	// regarded as failures with the reason TaskCanceled.
+	CollectAll bool
+}
+
+// Group is a struct which runs tasks concurrently and aggregates their Err(s).
+type Group struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	sem        chan struct{}
+	collectAll bool
+	wg         sync.WaitGroup
+	mutex      sync.Mutex
+	names      []string
+	errs       map[int]Err
+}
+
+// NewGroup is a function which creates a Group with a parent context and
+// options.
+// The context passed to tasks is derived from the parent context.
+func NewGroup(ctx context.Context, opts GroupOptions) *Group {
+	g := &Group{
+		collectAll: opts.CollectAll,
+		errs:       make(map[int]Err),
+	}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	if opts.Limit > 0 {
+		g.sem = make(chan struct{}, opts.Limit)
+	}
+	return g
+}
+
+// Go method runs the specified task in a new goroutine.
+// If the task panics, the panic is recovered with Try function and regarded
+// as the failure of the task.
+func (g *Group) Go(name string, fn func(ctx context.Context) Err) {
+	g.mutex.Lock()
+	index := len(g.names)
+	g.names = append(g.names, name)
+	g.mutex.Unlock()
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		if g.sem != nil {
+			g.sem <- struct{}{}
+			defer func() { <-g.sem }()
+		}
+
+		var err Err
+		if e := g.ctx.Err(); e != nil && !g.collectAll {
+			// This Err is not notified like the wrapping Err below, because the
+			// task did nothing.
+			err = Err{reason: TaskCanceled{}, cause: e}
+		} else {
+			err = Try(func() Err {
+				return fn(g.ctx)
+			})
+			if err.IsOk() {
+				return
+			}
+		}
+
+		g.mutex.Lock()
+		// The Err returned by the task has been notified already, so this
+		// wrapping Err is not notified.
+		g.errs[index] = Err{reason: TaskFailed{Task: name}, cause: err}
+		g.mutex.Unlock()
+
+		if !g.collectAll {
+			g.cancel()
+		}
+	}()
+}
+
+// Wait method waits for all tasks to finish, and returns Ok if all tasks
+// succeeded, or an Err of which reason is TasksFailed otherwise.
+// The cause of the Err is an Errs which contains the Err(s) of the failed
+// tasks in the order of calling Go method.
+func (g *Group) Wait() Err {
+	g.wg.Wait()
+	g.cancel()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if len(g.errs) == 0 {
+		return ok
+	}
+
+	errs := make(Errs, 0, len(g.errs))
+	for i := range g.names {
+		if err, ok := g.errs[i]; ok {
+			errs = append(errs, err)
+		}
+	}
+
+	var err Err
+	err.reason = TasksFailed{Failed: len(errs), Total: len(g.names)}
+	err.cause = errs
+
+	if isErrNotifiable() {
+		notifyErrWithOccasion(err, newErrOccasion(runtime.Caller(1)))
+	}
+
+	return err
+}
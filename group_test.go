@@ -0,0 +1,207 @@
+package reasonederror_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+func TestGroup_allSucceed(t *testing.T) {
+	g := re.NewGroup(context.Background(), re.GroupOptions{})
+
+	var n int32
+	for i := 0; i < 5; i++ {
+		g.Go("task", func(ctx context.Context) re.Err {
+			atomic.AddInt32(&n, 1)
+			return re.Ok()
+		})
+	}
+
+	err := g.Wait()
+	assert.True(t, err.IsOk())
+	assert.Equal(t, atomic.LoadInt32(&n), int32(5))
+}
+
+func TestGroup_collectAll(t *testing.T) {
+	g := re.NewGroup(context.Background(), re.GroupOptions{CollectAll: true})
+
+	cause := errors.New("def")
+	g.Go("a", func(ctx context.Context) re.Err {
+		time.Sleep(20 * time.Millisecond)
+		return re.NewErr(InvalidValue{Value: "x"}, cause)
+	})
+	g.Go("b", func(ctx context.Context) re.Err {
+		return re.Ok()
+	})
+	g.Go("c", func(ctx context.Context) re.Err {
+		return re.NewErr(FailToGetValue{Name: "y"})
+	})
+	g.Go("d", func(ctx context.Context) re.Err {
+		time.Sleep(40 * time.Millisecond)
+		assert.Nil(t, ctx.Err())
+		return re.NewErr(FailToGetValue{Name: "z"})
+	})
+
+	err := g.Wait()
+	switch r := err.Reason().(type) {
+	case re.TasksFailed:
+		assert.Equal(t, r.Failed, 3)
+		assert.Equal(t, r.Total, 4)
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	errs := err.Cause().(re.Errs)
+	assert.Equal(t, len(errs), 3)
+
+	assert.Equal(t, errs[0].ReasonName(), "TaskFailed")
+	assert.Equal(t, errs[0].Situation(), map[string]interface{}{"Task": "a", "Value": "x"})
+	assert.Equal(t, errs[1].Situation(), map[string]interface{}{"Task": "c", "Name": "y"})
+	assert.Equal(t, errs[2].Situation(), map[string]interface{}{"Task": "d", "Name": "z"})
+
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestGroup_cancelOnFirstError(t *testing.T) {
+	g := re.NewGroup(context.Background(), re.GroupOptions{Limit: 1})
+
+	var started []string
+	g.Go("a", func(ctx context.Context) re.Err {
+		started = append(started, "a")
+		return re.NewErr(InvalidValue{Value: "x"})
+	})
+	time.Sleep(20 * time.Millisecond)
+	g.Go("b", func(ctx context.Context) re.Err {
+		started = append(started, "b")
+		return re.Ok()
+	})
+
+	err := g.Wait()
+	assert.Equal(t, started, []string{"a"})
+	assert.Equal(t, err.Get("Failed"), 2)
+	assert.Equal(t, err.Get("Total"), 2)
+
+	errs := err.Cause().(re.Errs)
+	assert.Equal(t, errs[1].Get("Task"), "b")
+	assert.Equal(t, errs[1].Cause().(re.Err).ReasonName(), "TaskCanceled")
+	assert.True(t, errors.Is(errs[1], context.Canceled))
+}
+
+func TestGroup_cancelRunningTasks(t *testing.T) {
+	g := re.NewGroup(context.Background(), re.GroupOptions{})
+
+	started := make(chan struct{})
+	g.Go("wait", func(ctx context.Context) re.Err {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return re.NewErr(FailToGetValue{Name: "canceled"})
+		case <-time.After(time.Second):
+			return re.Ok()
+		}
+	})
+	g.Go("fail", func(ctx context.Context) re.Err {
+		<-started
+		return re.NewErr(InvalidValue{Value: "x"})
+	})
+
+	err := g.Wait()
+	errs := err.Cause().(re.Errs)
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs[0].Get("Task"), "wait")
+	assert.Equal(t, errs[0].Get("Name"), "canceled")
+	assert.Equal(t, errs[1].Get("Task"), "fail")
+}
+
+func TestGroup_limit(t *testing.T) {
+	g := re.NewGroup(context.Background(), re.GroupOptions{Limit: 2, CollectAll: true})
+
+	var running, maxRunning int32
+	for i := 0; i < 6; i++ {
+		g.Go("task", func(ctx context.Context) re.Err {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return re.Ok()
+		})
+	}
+
+	err := g.Wait()
+	assert.True(t, err.IsOk())
+	assert.Equal(t, atomic.LoadInt32(&maxRunning), int32(2))
+}
+
+func TestGroup_panic(t *testing.T) {
+	g := re.NewGroup(context.Background(), re.GroupOptions{CollectAll: true})
+
+	g.Go("panic", func(ctx context.Context) re.Err {
+		panic("boom")
+	})
+
+	err := g.Wait()
+	errs := err.Cause().(re.Errs)
+	assert.Equal(t, len(errs), 1)
+	assert.Equal(t, errs[0].Get("Task"), "panic")
+	assert.Equal(t, errs[0].Cause().(re.Err).ReasonName(), "Panicked")
+	assert.Equal(t, errs[0].Get("Value"), "boom")
+}
+
+func TestGroup_parentContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	g := re.NewGroup(ctx, re.GroupOptions{})
+	g.Go("a", func(ctx context.Context) re.Err {
+		return re.NewErr(InvalidValue{})
+	})
+
+	err := g.Wait()
+	switch r := err.Reason().(type) {
+	case re.TasksFailed:
+		assert.Equal(t, r.Failed, 1)
+		assert.Equal(t, r.Total, 1)
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	e := err.Cause().(re.Errs)[0]
+	assert.Equal(t, e.Reason(), re.TaskFailed{Task: "a"})
+	assert.Equal(t, e.Cause().(re.Err).Reason(), re.TaskCanceled{})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestGroup_notification(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	var names []string
+	var occ re.ErrOccasion
+	re.AddSyncErrHandler(func(err re.Err, o re.ErrOccasion) {
+		names = append(names, err.ReasonName())
+		occ = o
+	})
+	re.FixErrCfgs()
+
+	g := re.NewGroup(context.Background(), re.GroupOptions{})
+	g.Go("a", func(ctx context.Context) re.Err {
+		return re.NewErr(InvalidValue{})
+	})
+	_, _, line, _ := runtime.Caller(0)
+	g.Wait()
+
+	assert.Equal(t, names, []string{"InvalidValue", "TasksFailed"})
+	assert.Equal(t, occ.File(), "group_test.go")
+	assert.Equal(t, occ.Line(), line+1)
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package grpcerr provides functions and interceptors to convert
+// reasonederror.Err(s) into gRPC statuses and to rebuild Err(s) from them.
+//
+// This package is a separate module so that applications which do not use
+// gRPC do not depend on it.
+package grpcerr
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/sttk/reasonederror"
+	"google.golang.org/grpc/codes"
+)
+
+// grpcCoder is an interface for reason structs which declare their gRPC
+// status codes by themselves.
+type grpcCoder interface {
+	GRPCCode() codes.Code
+}
+
+var (
+	reasonCodes = make(map[reflect.Type]codes.Code)
+	codeLock    = sync.RWMutex{}
+)
+
+// SetCode is a function which registers a gRPC status code for the type of
+// the specified reason.
+// If a reason struct has GRPCCode method, its result takes precedence over
+// the registered code.
+func SetCode(reason interface{}, code codes.Code) {
+	t := reasonederror.ReasonType(reason)
+	if t == nil {
+		return
+	}
+
+	codeLock.Lock()
+	defer codeLock.Unlock()
+
+	reasonCodes[t] = code
+}
+
+// Code is a function which returns a gRPC status code for the specified error.
+// If the error is or wraps a reasonederror.Err, the code is solved in the
+// following order:
+//
+//  1. the result of GRPCCode method of the reason struct,
+//  2. the code registered with SetCode function,
+//  3. codes.InvalidArgument if the Err is a user error,
+//  4. codes.Unknown.
+//
+// If the error is nil or Ok, this function returns codes.OK, and if the error
+// is not a reasonederror.Err, this function returns codes.Unknown.
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	var re reasonederror.Err
+	if !errors.As(err, &re) {
+		return codes.Unknown
+	}
+
+	return errCode(re)
+}
+
+func errCode(err reasonederror.Err) codes.Code {
+	if err.IsOk() {
+		return codes.OK
+	}
+
+	if c, ok := err.Reason().(grpcCoder); ok {
+		return c.GRPCCode()
+	}
+
+	codeLock.RLock()
+	code, ok := reasonCodes[reasonederror.ReasonType(err.Reason())]
+	codeLock.RUnlock()
+
+	if ok {
+		return code
+	}
+
+	if err.IsUserError() {
+		return codes.InvalidArgument
+	}
+
+	return codes.Unknown
+}
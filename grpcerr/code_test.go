@@ -0,0 +1,69 @@
+package grpcerr_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/grpcerr"
+	"google.golang.org/grpc/codes"
+)
+
+type /* error reasons */ (
+	ServiceNotFound struct {
+		Service string
+	}
+	InvalidServiceName struct {
+		Name string
+	}
+	QuotaExceeded struct {
+		Limit int
+	}
+	FailToCheckHealth struct {
+		Service string
+		Token   string `redact:"true"`
+	}
+	FailToAuthenticate struct {
+		User     string
+		Password string `redact:"true"`
+	}
+)
+
+func (r ServiceNotFound) GRPCCode() codes.Code {
+	return codes.NotFound
+}
+
+func (r InvalidServiceName) Class() reasonederror.Class {
+	return reasonederror.ClassUserError
+}
+
+func (r InvalidServiceName) Message() string {
+	return "the service name is invalid: " + r.Name
+}
+
+func TestCode(t *testing.T) {
+	grpcerr.SetCode(QuotaExceeded{}, codes.ResourceExhausted)
+	grpcerr.SetCode(ServiceNotFound{}, codes.Unavailable)
+	grpcerr.SetCode(nil, codes.Aborted)
+
+	assert.Equal(t, grpcerr.Code(nil), codes.OK)
+	assert.Equal(t, grpcerr.Code(reasonederror.Ok()), codes.OK)
+	assert.Equal(t, grpcerr.Code(errors.New("x")), codes.Unknown)
+
+	err := reasonederror.NewErr(ServiceNotFound{})
+	assert.Equal(t, grpcerr.Code(err), codes.NotFound)
+
+	err = reasonederror.NewErr(&InvalidServiceName{})
+	assert.Equal(t, grpcerr.Code(err), codes.InvalidArgument)
+
+	err = reasonederror.NewErr(&QuotaExceeded{})
+	assert.Equal(t, grpcerr.Code(err), codes.ResourceExhausted)
+
+	err = reasonederror.NewErr(FailToCheckHealth{})
+	assert.Equal(t, grpcerr.Code(err), codes.Unknown)
+
+	wrapped := fmt.Errorf("wrapped: %w", reasonederror.NewErr(QuotaExceeded{}))
+	assert.Equal(t, grpcerr.Code(wrapped), codes.ResourceExhausted)
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package grpcerr
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/sttk/reasonederror"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor is a function which returns a gRPC unary server
+// interceptor converting reasonederror.Err(s) returned by handlers into gRPC
+// statuses with ToStatus function.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{},
+		info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, toStatusErr(err)
+	}
+}
+
+// StreamServerInterceptor is a function which returns a gRPC stream server
+// interceptor converting reasonederror.Err(s) returned by handlers into gRPC
+// statuses with ToStatus function.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, ss grpc.ServerStream,
+		info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		return toStatusErr(handler(srv, ss))
+	}
+}
+
+// UnaryClientInterceptor is a function which returns a gRPC unary client
+// interceptor rebuilding reasonederror.Err(s) from received error statuses
+// with FromStatus function.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context, method string, req, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption,
+	) error {
+		return fromStatusErr(invoker(ctx, method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor is a function which returns a gRPC stream client
+// interceptor rebuilding reasonederror.Err(s) from received error statuses
+// with FromStatus function.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, fromStatusErr(err)
+		}
+		return &clientStream{cs}, nil
+	}
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	return fromStatusErr(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return fromStatusErr(s.ClientStream.RecvMsg(m))
+}
+
+func toStatusErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var re reasonederror.Err
+	if !errors.As(err, &re) {
+		return err
+	}
+
+	st := ToStatus(re)
+	if st == nil {
+		return nil
+	}
+	return st.Err()
+}
+
+func fromStatusErr(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	return FromStatus(st)
+}
@@ -0,0 +1,142 @@
+package grpcerr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/grpcerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+}
+
+func (s *healthServer) Check(
+	ctx context.Context, req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	switch req.Service {
+	case "ok":
+		return &healthpb.HealthCheckResponse{
+			Status: healthpb.HealthCheckResponse_SERVING,
+		}, reasonederror.Ok()
+	case "plain":
+		return nil, status.Error(codes.Unavailable, "plain")
+	default:
+		return nil, reasonederror.NewErr(ServiceNotFound{Service: req.Service})
+	}
+}
+
+func (s *healthServer) Watch(
+	req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer,
+) error {
+	stream.Send(&healthpb.HealthCheckResponse{
+		Status: healthpb.HealthCheckResponse_SERVING,
+	})
+	if req.Service == "ok" {
+		return nil
+	}
+	return reasonederror.NewErr(QuotaExceeded{Limit: 3})
+}
+
+func newClient(t *testing.T) (healthpb.HealthClient, func()) {
+	lis := bufconn.Listen(1 << 20)
+
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(grpcerr.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(grpcerr.StreamServerInterceptor()),
+	)
+	healthpb.RegisterHealthServer(srv, &healthServer{})
+	go srv.Serve(lis)
+
+	conn, e := grpc.DialContext(context.Background(), "passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(grpcerr.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(grpcerr.StreamClientInterceptor()),
+	)
+	assert.Nil(t, e)
+
+	return healthpb.NewHealthClient(conn), func() {
+		conn.Close()
+		srv.Stop()
+	}
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	grpcerr.RegisterReason(ServiceNotFound{})
+
+	client, stop := newClient(t)
+	defer stop()
+
+	ctx := context.Background()
+
+	resp, e := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "ok"})
+	assert.Nil(t, e)
+	assert.Equal(t, resp.Status, healthpb.HealthCheckResponse_SERVING)
+
+	_, e = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "foo"})
+	assert.Equal(t, status.Code(e), codes.NotFound)
+	var err reasonederror.Err
+	assert.True(t, errors.As(e, &err))
+	switch r := err.Reason().(type) {
+	case ServiceNotFound:
+		assert.Equal(t, r.Service, "foo")
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	_, e = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "plain"})
+	assert.Equal(t, status.Code(e), codes.Unavailable)
+	assert.True(t, errors.As(e, &err))
+	switch r := err.Reason().(type) {
+	case grpcerr.RemoteStatus:
+		assert.Equal(t, r.Code, codes.Unavailable)
+		assert.Equal(t, r.Message, "plain")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	grpcerr.RegisterReason(QuotaExceeded{})
+
+	client, stop := newClient(t)
+	defer stop()
+
+	ctx := context.Background()
+
+	stream, e := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "ok"})
+	assert.Nil(t, e)
+	resp, e := stream.Recv()
+	assert.Nil(t, e)
+	assert.Equal(t, resp.Status, healthpb.HealthCheckResponse_SERVING)
+	_, e = stream.Recv()
+	assert.Equal(t, e, io.EOF)
+
+	stream, e = client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "ng"})
+	assert.Nil(t, e)
+	_, e = stream.Recv()
+	assert.Nil(t, e)
+	_, e = stream.Recv()
+	var err reasonederror.Err
+	assert.True(t, errors.As(e, &err))
+	switch r := err.Reason().(type) {
+	case QuotaExceeded:
+		assert.Equal(t, r.Limit, 3)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package grpcerr
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/sttk/reasonederror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type /* error reasons */ (
+	// RemoteStatus is an error reason which indicates that a server responded
+	// with an error status of which reason is not registered with
+	// RegisterReason function, or without an ErrorInfo detail.
+	RemoteStatus struct {
+		Code    codes.Code
+		Message string
+		Reason  string
+		Domain  string
+	}
+)
+
+var (
+	reasons    = make(map[string]reflect.Type)
+	reasonLock = sync.RWMutex{}
+)
+
+// RegisterReason is a function which registers the type of the specified
+// reason so that FromStatus function can rebuild an Err of the reason from a
+// gRPC status.
+func RegisterReason(reason interface{}) {
+	t := reasonederror.ReasonType(reason)
+	if t == nil {
+		return
+	}
+
+	reasonLock.Lock()
+	defer reasonLock.Unlock()
+
+	reasons[t.PkgPath()+"."+t.Name()] = t
+}
+
+// ToStatus is a function which converts the specified error into a gRPC
+// status.
+//
+// If the error is or wraps a reasonederror.Err, the status has the code
+// returned by Code function and an errdetails.ErrorInfo detail, of which
+// Reason is the reason name, Domain is the reason package, and Metadata holds
+// the redacted situation of the Err with JSON-encoded values.
+// The message of the status is the result of RedactedMessage method if it is
+// not empty, or the reason name otherwise.
+//
+// If the error already has a gRPC status, this function returns it, and if
+// the error is nil or Ok, this function returns nil.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+
+	var re reasonederror.Err
+	if !errors.As(err, &re) {
+		st, _ := status.FromError(err)
+		return st
+	}
+
+	if re.IsOk() {
+		return nil
+	}
+
+	msg := re.RedactedMessage()
+	if msg == "" {
+		msg = re.ReasonName()
+	}
+
+	info := &errdetails.ErrorInfo{
+		Reason:   re.ReasonName(),
+		Domain:   re.ReasonPackage(),
+		Metadata: make(map[string]string),
+	}
+	for k, v := range re.RedactedSituation() {
+		b, e := json.Marshal(v)
+		if e != nil {
+			continue
+		}
+		info.Metadata[k] = string(b)
+	}
+
+	st := status.New(errCode(re), msg)
+	if withDetails, e := st.WithDetails(info); e == nil {
+		st = withDetails
+	}
+	return st
+}
+
+// FromStatus is a function which rebuilds an Err from the specified gRPC
+// status.
+//
+// If the status has an errdetails.ErrorInfo detail of which reason is
+// registered with RegisterReason function, this function returns an Err of
+// the registered reason type, of which fields are filled with the metadata of
+// the same names.
+// Otherwise, this function returns an Err of which reason is RemoteStatus.
+// The cause of the Err is the error of the status, so that status.Code and
+// status.FromError functions work with the Err as with the original error.
+// If the status is nil or its code is codes.OK, this function returns Ok.
+func FromStatus(st *status.Status) reasonederror.Err {
+	if st == nil || st.Code() == codes.OK {
+		return reasonederror.Ok()
+	}
+
+	unknown := RemoteStatus{Code: st.Code(), Message: st.Message()}
+
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if i, ok := d.(*errdetails.ErrorInfo); ok {
+			info = i
+			break
+		}
+	}
+	if info == nil {
+		return reasonederror.NewErr(unknown, st.Err())
+	}
+
+	unknown.Reason = info.Reason
+	unknown.Domain = info.Domain
+
+	reasonLock.RLock()
+	t, ok := reasons[info.Domain+"."+info.Reason]
+	reasonLock.RUnlock()
+
+	if !ok {
+		return reasonederror.NewErr(unknown, st.Err())
+	}
+
+	v := reflect.New(t).Elem()
+
+	n := t.NumField()
+	for i := 0; i < n; i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		s, ok := info.Metadata[sf.Name]
+		if !ok {
+			continue
+		}
+		// A field which cannot be decoded, like a redacted field, is left as
+		// its zero value.
+		json.Unmarshal([]byte(s), v.Field(i).Addr().Interface())
+	}
+
+	return reasonederror.NewErr(v.Interface(), st.Err())
+}
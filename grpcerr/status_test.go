@@ -0,0 +1,138 @@
+package grpcerr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/grpcerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	cause := reasonederror.NewErr(ServiceNotFound{Service: "foo"})
+	err := reasonederror.NewErr(FailToCheckHealth{Service: "bar", Token: "t"}, cause)
+
+	st := grpcerr.ToStatus(err)
+	assert.Equal(t, st.Code(), codes.Unknown)
+	assert.Equal(t, st.Message(), "FailToCheckHealth")
+
+	details := st.Details()
+	assert.Equal(t, len(details), 1)
+	info := details[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, info.Reason, "FailToCheckHealth")
+	assert.Equal(t, info.Domain, "github.com/sttk/reasonederror/grpcerr_test")
+	assert.Equal(t, info.Metadata, map[string]string{
+		"Service": `"bar"`,
+		"Token":   `"[REDACTED]"`,
+	})
+}
+
+func TestToStatus_withMessage(t *testing.T) {
+	st := grpcerr.ToStatus(reasonederror.NewErr(InvalidServiceName{Name: "?"}))
+	assert.Equal(t, st.Code(), codes.InvalidArgument)
+	assert.Equal(t, st.Message(), "the service name is invalid: ?")
+}
+
+func TestToStatus_redactedMessage(t *testing.T) {
+	reasonederror.SetReasonMessage(FailToAuthenticate{}, "{{.User}} could not authenticate with {{.Password}}")
+
+	st := grpcerr.ToStatus(reasonederror.NewErr(FailToAuthenticate{User: "foo", Password: "secret"}))
+	assert.Equal(t, st.Message(), "foo could not authenticate with [REDACTED]")
+}
+
+func TestToStatus_notErr(t *testing.T) {
+	assert.Nil(t, grpcerr.ToStatus(nil))
+	assert.Nil(t, grpcerr.ToStatus(reasonederror.Ok()))
+
+	st := grpcerr.ToStatus(errors.New("x"))
+	assert.Equal(t, st.Code(), codes.Unknown)
+	assert.Equal(t, st.Message(), "x")
+
+	st = grpcerr.ToStatus(status.Error(codes.Canceled, "y"))
+	assert.Equal(t, st.Code(), codes.Canceled)
+	assert.Equal(t, st.Message(), "y")
+}
+
+func TestFromStatus_registeredReason(t *testing.T) {
+	grpcerr.RegisterReason(QuotaExceeded{})
+
+	st := grpcerr.ToStatus(reasonederror.NewErr(QuotaExceeded{Limit: 10}))
+
+	err := grpcerr.FromStatus(st)
+	switch r := err.Reason().(type) {
+	case QuotaExceeded:
+		assert.Equal(t, r.Limit, 10)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestFromStatus_redactedField(t *testing.T) {
+	grpcerr.RegisterReason(&FailToCheckHealth{})
+
+	st := grpcerr.ToStatus(reasonederror.NewErr(FailToCheckHealth{Service: "a", Token: "t"}))
+
+	err := grpcerr.FromStatus(st)
+	switch r := err.Reason().(type) {
+	case FailToCheckHealth:
+		assert.Equal(t, r.Service, "a")
+		assert.Equal(t, r.Token, reasonederror.RedactedValue)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestFromStatus_unknownReason(t *testing.T) {
+	st := status.New(codes.NotFound, "missing")
+	st, _ = st.WithDetails(&errdetails.ErrorInfo{Reason: "Missing", Domain: "example.com"})
+
+	err := grpcerr.FromStatus(st)
+	switch r := err.Reason().(type) {
+	case grpcerr.RemoteStatus:
+		assert.Equal(t, r.Code, codes.NotFound)
+		assert.Equal(t, r.Message, "missing")
+		assert.Equal(t, r.Reason, "Missing")
+		assert.Equal(t, r.Domain, "example.com")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestFromStatus_noErrorInfo(t *testing.T) {
+	err := grpcerr.FromStatus(status.New(codes.Internal, "oops"))
+	switch r := err.Reason().(type) {
+	case grpcerr.RemoteStatus:
+		assert.Equal(t, r.Code, codes.Internal)
+		assert.Equal(t, r.Message, "oops")
+		assert.Equal(t, r.Reason, "")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestFromStatus_keepsStatus(t *testing.T) {
+	grpcerr.RegisterReason(QuotaExceeded{})
+
+	st := grpcerr.ToStatus(reasonederror.NewErr(QuotaExceeded{Limit: 10}))
+	err := grpcerr.FromStatus(st)
+	assert.Equal(t, err.ReasonName(), "QuotaExceeded")
+	assert.Equal(t, status.Code(err), st.Code())
+
+	s, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, s.Code(), st.Code())
+	assert.Equal(t, len(s.Details()), 1)
+
+	err = grpcerr.FromStatus(status.New(codes.NotFound, "missing"))
+	assert.Equal(t, err.ReasonName(), "RemoteStatus")
+	assert.Equal(t, status.Code(err), codes.NotFound)
+}
+
+func TestFromStatus_ok(t *testing.T) {
+	assert.True(t, grpcerr.FromStatus(nil).IsOk())
+	assert.True(t, grpcerr.FromStatus(status.New(codes.OK, "")).IsOk())
+}
@@ -0,0 +1,329 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"math/rand"
+	"reflect"
+	"runtime/debug"
+)
+
+type /* error reasons */ (
+	// FailToCloseHandlers is an error reason which indicates that some of the
+	// registered Err notification handlers failed to be closed.
+	// The cause is an Errs which contains the Err(s) returned by Close methods
+	// of the handlers.
+	FailToCloseHandlers struct {
+		Count int
+	}
+)
+
+// Handler is an interface of Err notification handlers.
+//
+// A Handler can also have Close method of which signature is Close() Err,
+// like webhook.Webhook and jsonlines.Sink.
+// The Close methods of the Handler(s) registered with AddSyncHandler or
+// AddAsyncHandler function are called by CloseErrHandlers function.
+type Handler interface {
+	Handle(Err, ErrOccasion)
+}
+
+// HandlerFunc is a function type which adapts a function to a Handler.
+type HandlerFunc func(Err, ErrOccasion)
+
+// Handle method calls this function.
+func (fn HandlerFunc) Handle(err Err, occ ErrOccasion) {
+	fn(err, occ)
+}
+
+// closer is an interface for Handler(s) which have Close method.
+type closer interface {
+	Close() Err
+}
+
+var errHandlerClosers []closer
+
+// AddSyncHandler is a function which adds a Handler executed synchronously,
+// like AddSyncErrHandler function.
+func AddSyncHandler(handler Handler) {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return
+	}
+
+	addErrHandler(&syncErrHandlers, handler.Handle)
+	addErrHandlerCloser(handler)
+}
+
+// AddAsyncHandler is a function which adds a Handler executed
+// asynchronously, like AddAsyncErrHandler function.
+func AddAsyncHandler(handler Handler) {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return
+	}
+
+	addErrHandler(&asyncErrHandlers, handler.Handle)
+	addErrHandlerCloser(handler)
+}
+
+func addErrHandlerCloser(handler Handler) {
+	if c, ok := handler.(closer); ok {
+		errHandlerClosers = append(errHandlerClosers, c)
+	}
+}
+
+// CloseErrHandlers is a function which calls Close methods of the Handler(s)
+// registered with AddSyncHandler or AddAsyncHandler function, in the order of
+// registration.
+// This function should be called at the end of an application, and each
+// Handler is closed only once even if this function is called again.
+// If some of Close methods return Err(s), this function returns an Err of
+// which reason is FailToCloseHandlers.
+func CloseErrHandlers() Err {
+	errCfgMutex.Lock()
+	closers := errHandlerClosers
+	errHandlerClosers = nil
+	errCfgMutex.Unlock()
+
+	var errs Errs
+	for _, c := range closers {
+		if err := c.Close(); err.IsNotOk() {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return NewErr(FailToCloseHandlers{Count: len(errs)}, errs)
+	}
+	return Ok()
+}
+
+// Middleware is a function type which wraps a Handler with another Handler,
+// like filtering, sampling, redaction, and enrichment of notifications.
+// The enrichment for all Handler(s) is done by enrichers added with
+// AddErrOccasionEnricher function, and Enrich Middleware is for specific
+// Handler(s).
+type Middleware func(next Handler) Handler
+
+// HandlerChain is a struct which has a list of Middleware(s) to be applied to
+// Handler(s).
+// A HandlerChain is created with Chain function, and can be shared by
+// several Handler(s).
+type HandlerChain struct {
+	middlewares []Middleware
+}
+
+// Chain is a function which creates a HandlerChain with the specified
+// Middleware(s).
+// The first Middleware is the outermost, which receives notifications first.
+//
+//	chain := reasonederror.Chain(
+//	    reasonederror.RecoverPanic(nil),
+//	    reasonederror.Filter(func(err reasonederror.Err, occ reasonederror.ErrOccasion) bool {
+//	        return err.Severity() >= reasonederror.SeverityWarn
+//	    }),
+//	    reasonederror.Redact(),
+//	)
+//	reasonederror.AddAsyncHandler(chain.Then(hook))
+//	reasonederror.AddAsyncHandler(chain.Then(sink))
+func Chain(middlewares ...Middleware) HandlerChain {
+	a := make([]Middleware, len(middlewares))
+	copy(a, middlewares)
+	return HandlerChain{middlewares: a}
+}
+
+// Append method returns a new HandlerChain which has the Middleware(s) of
+// this HandlerChain followed by the specified Middleware(s).
+func (c HandlerChain) Append(middlewares ...Middleware) HandlerChain {
+	a := make([]Middleware, 0, len(c.middlewares)+len(middlewares))
+	a = append(a, c.middlewares...)
+	a = append(a, middlewares...)
+	return HandlerChain{middlewares: a}
+}
+
+// Then method returns a Handler which passes notifications through the
+// Middleware(s) of this HandlerChain to the specified Handler.
+// If the specified Handler has Close method, the returned Handler also has
+// Close method which calls it.
+func (c HandlerChain) Then(handler Handler) Handler {
+	h := handler
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+
+	if cl, ok := handler.(closer); ok {
+		return chainedCloser{h, cl}
+	}
+	return chainedHandler{h}
+}
+
+// ThenFunc method is same as Then method, but takes a function as a Handler.
+func (c HandlerChain) ThenFunc(fn func(Err, ErrOccasion)) Handler {
+	return c.Then(HandlerFunc(fn))
+}
+
+type chainedHandler struct {
+	handler Handler
+}
+
+func (h chainedHandler) Handle(err Err, occ ErrOccasion) {
+	h.handler.Handle(err, occ)
+}
+
+type chainedCloser struct {
+	handler Handler
+	closer  closer
+}
+
+func (h chainedCloser) Handle(err Err, occ ErrOccasion) {
+	h.handler.Handle(err, occ)
+}
+
+func (h chainedCloser) Close() Err {
+	return h.closer.Close()
+}
+
+// Filter is a function which creates a Middleware passing only the
+// notifications for which the specified function returns true.
+func Filter(fn func(Err, ErrOccasion) bool) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(err Err, occ ErrOccasion) {
+			if fn(err, occ) {
+				next.Handle(err, occ)
+			}
+		})
+	}
+}
+
+// Sample is a function which creates a Middleware passing notifications at
+// random with the specified rate, which is from 0.0 (none) to 1.0 (all).
+// This is useful to reduce notifications of frequent Err(s) to a costly
+// Handler, and can be combined with Filter to sample only low severities.
+func Sample(rate float64) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(err Err, occ ErrOccasion) {
+			if rate >= 1.0 || (rate > 0.0 && rand.Float64() < rate) {
+				next.Handle(err, occ)
+			}
+		})
+	}
+}
+
+// Enrich is a function which creates a Middleware passing ErrOccasion(s) to
+// which the specified function attaches attributes with SetAttr method.
+// Unlike enrichers added with AddErrOccasionEnricher function, the attributes
+// are visible only to the following Handler(s).
+//
+//	chain := reasonederror.Chain(
+//	    reasonederror.Enrich(func(err reasonederror.Err, occ *reasonederror.ErrOccasion) {
+//	        occ.SetAttr("fingerprint", err.Fingerprint())
+//	    }),
+//	)
+func Enrich(fn func(Err, *ErrOccasion)) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(err Err, occ ErrOccasion) {
+			fn(err, &occ)
+			next.Handle(err, occ)
+		})
+	}
+}
+
+// Redact is a function which creates a Middleware passing Err(s) of which
+// reason struct fields tagged with `redact:"true"` are cleared.
+// The values of string fields and interface{} fields are replaced with
+// RedactedValue, and the others are replaced with zero values.
+// The Err(s) in the cause chain are also redacted, but the causes other than
+// Err are kept as they are, so their texts may still be in the result of
+// Error method.
+func Redact() Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(err Err, occ ErrOccasion) {
+			next.Handle(redactErr(err), occ)
+		})
+	}
+}
+
+func redactErr(err Err) Err {
+	switch cause := err.cause.(type) {
+	case Err:
+		err.cause = redactErr(cause)
+	case Errs:
+		a := make(Errs, len(cause))
+		for i, e := range cause {
+			a[i] = redactErr(e)
+		}
+		err.cause = a
+	}
+
+	if err.reason == nil {
+		return err
+	}
+
+	v := reflect.ValueOf(err.reason)
+	isPtr := v.Kind() == reflect.Ptr
+	if isPtr {
+		if v.IsNil() {
+			return err
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return err
+	}
+
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+
+	t := v.Type()
+	redacted := reflect.ValueOf(RedactedValue)
+
+	n := c.NumField()
+	for i := 0; i < n; i++ {
+		f := c.Field(i)
+		if t.Field(i).Tag.Get("redact") != "true" || !f.CanSet() {
+			continue
+		}
+		switch {
+		case f.Kind() == reflect.String:
+			f.SetString(RedactedValue)
+		case f.Kind() == reflect.Interface && redacted.Type().AssignableTo(f.Type()):
+			f.Set(redacted)
+		default:
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+
+	if isPtr {
+		err.reason = c.Addr().Interface()
+	} else {
+		err.reason = c.Interface()
+	}
+	return err
+}
+
+// RecoverPanic is a function which creates a Middleware recovering panics in
+// the following Handler(s), so that a failing Handler does not break an
+// application or other Handler(s).
+// The specified function is called with the panic value and the stack trace,
+// and if it is nil, panics are silently discarded.
+// The function should not create Err(s) with NewErr function, because they
+// are notified to the Handler(s) again.
+func RecoverPanic(fn func(v interface{}, stack []byte)) Middleware {
+	return func(next Handler) Handler {
+		return HandlerFunc(func(err Err, occ ErrOccasion) {
+			defer func() {
+				if v := recover(); v != nil && fn != nil {
+					fn(v, debug.Stack())
+				}
+			}()
+			next.Handle(err, occ)
+		})
+	}
+}
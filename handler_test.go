@@ -0,0 +1,250 @@
+package reasonederror
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type /* error reasons */ (
+	ReasonForHandler struct {
+		Name     string
+		Password string      `redact:"true"`
+		Token    interface{} `redact:"true"`
+		Code     int         `redact:"true"`
+	}
+	ReasonForHandlerCause struct {
+		Key string `redact:"true"`
+	}
+)
+
+type recordingHandler struct {
+	names    []string
+	closed   int
+	closeErr Err
+}
+
+func (h *recordingHandler) Handle(err Err, occ ErrOccasion) {
+	h.names = append(h.names, err.ReasonName())
+}
+
+func (h *recordingHandler) Close() Err {
+	h.closed++
+	return h.closeErr
+}
+
+func TestHandlerFunc(t *testing.T) {
+	var names []string
+	var h Handler = HandlerFunc(func(err Err, occ ErrOccasion) {
+		names = append(names, err.ReasonName())
+	})
+
+	h.Handle(Err{reason: ReasonForHandler{}}, ErrOccasion{})
+	assert.Equal(t, names, []string{"ReasonForHandler"})
+}
+
+func TestAddSyncHandler(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	h1 := &recordingHandler{}
+	h2 := &recordingHandler{}
+	AddSyncErrHandler(func(err Err, occ ErrOccasion) {
+		h1.names = append(h1.names, "func")
+	})
+	AddSyncHandler(h1)
+	AddSyncHandler(HandlerFunc(h2.Handle))
+	FixErrCfgs()
+
+	AddSyncHandler(h2)
+
+	NewErr(ReasonForHandler{})
+
+	assert.Equal(t, h1.names, []string{"func", "ReasonForHandler"})
+	assert.Equal(t, h2.names, []string{"ReasonForHandler"})
+	assert.Equal(t, len(errHandlerClosers), 1)
+}
+
+func TestAddAsyncHandler(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	ch := make(chan string, 1)
+	AddAsyncHandler(HandlerFunc(func(err Err, occ ErrOccasion) {
+		ch <- err.ReasonName()
+	}))
+	FixErrCfgs()
+
+	NewErr(ReasonForHandler{})
+
+	select {
+	case name := <-ch:
+		assert.Equal(t, name, "ReasonForHandler")
+	case <-time.After(time.Second):
+		assert.Fail(t, "not notified")
+	}
+}
+
+func TestCloseErrHandlers(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	h1 := &recordingHandler{}
+	h2 := &recordingHandler{closeErr: Err{reason: ReasonForHandlerCause{}}}
+	h3 := &recordingHandler{}
+	AddSyncHandler(h1)
+	AddAsyncHandler(h2)
+	AddSyncHandler(Chain().Then(h3))
+	FixErrCfgs()
+
+	err := CloseErrHandlers()
+	switch r := err.Reason().(type) {
+	case FailToCloseHandlers:
+		assert.Equal(t, r.Count, 1)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	errs := err.Cause().(Errs)
+	assert.Equal(t, errs[0].ReasonName(), "ReasonForHandlerCause")
+
+	assert.Equal(t, h1.closed, 1)
+	assert.Equal(t, h2.closed, 1)
+	assert.Equal(t, h3.closed, 1)
+
+	assert.True(t, CloseErrHandlers().IsOk())
+	assert.Equal(t, h1.closed, 1)
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return HandlerFunc(func(err Err, occ ErrOccasion) {
+				calls = append(calls, name)
+				next.Handle(err, occ)
+			})
+		}
+	}
+
+	chain := Chain(mw("a"), mw("b"))
+	h := &recordingHandler{}
+
+	chain.Append(mw("c")).Then(h).Handle(Err{reason: ReasonForHandler{}}, ErrOccasion{})
+	assert.Equal(t, calls, []string{"a", "b", "c"})
+	assert.Equal(t, h.names, []string{"ReasonForHandler"})
+
+	calls = nil
+	chain.ThenFunc(h.Handle).Handle(Err{reason: ReasonForHandler{}}, ErrOccasion{})
+	assert.Equal(t, calls, []string{"a", "b"})
+
+	_, ok := chain.Then(h).(closer)
+	assert.True(t, ok)
+	_, ok = chain.ThenFunc(h.Handle).(closer)
+	assert.False(t, ok)
+}
+
+func TestFilter(t *testing.T) {
+	h := &recordingHandler{}
+	f := Filter(func(err Err, occ ErrOccasion) bool {
+		return err.Get("Name") == "a"
+	})(h)
+
+	f.Handle(Err{reason: ReasonForHandler{Name: "a"}}, ErrOccasion{})
+	f.Handle(Err{reason: ReasonForHandler{Name: "b"}}, ErrOccasion{})
+	assert.Equal(t, len(h.names), 1)
+}
+
+func TestSample(t *testing.T) {
+	err := Err{reason: ReasonForHandler{}}
+
+	h := &recordingHandler{}
+	s := Sample(0.0)(h)
+	for i := 0; i < 100; i++ {
+		s.Handle(err, ErrOccasion{})
+	}
+	assert.Equal(t, len(h.names), 0)
+
+	h = &recordingHandler{}
+	s = Sample(1.0)(h)
+	for i := 0; i < 100; i++ {
+		s.Handle(err, ErrOccasion{})
+	}
+	assert.Equal(t, len(h.names), 100)
+
+	h = &recordingHandler{}
+	s = Sample(0.5)(h)
+	for i := 0; i < 1000; i++ {
+		s.Handle(err, ErrOccasion{})
+	}
+	assert.True(t, len(h.names) > 300 && len(h.names) < 700, len(h.names))
+}
+
+func TestEnrich(t *testing.T) {
+	var got ErrOccasion
+	h := Enrich(func(err Err, occ *ErrOccasion) {
+		occ.SetAttr("name", err.Get("Name"))
+	})(HandlerFunc(func(err Err, occ ErrOccasion) {
+		got = occ
+	}))
+
+	var occ ErrOccasion
+	occ.SetAttr("service", "foo")
+	h.Handle(Err{reason: ReasonForHandler{Name: "a"}}, occ)
+
+	assert.Equal(t, got.Attrs(), map[string]interface{}{"service": "foo", "name": "a"})
+	assert.Equal(t, occ.Attrs(), map[string]interface{}{"service": "foo"})
+}
+
+func TestRedact(t *testing.T) {
+	var got Err
+	h := Redact()(HandlerFunc(func(err Err, occ ErrOccasion) {
+		got = err
+	}))
+
+	base := errors.New("base")
+	cause := Err{reason: &ReasonForHandlerCause{Key: "k1"}, cause: base}
+	err := Err{
+		reason: ReasonForHandler{Name: "a", Password: "p", Token: 123, Code: 9},
+		cause:  Errs{cause},
+	}
+	h.Handle(err, ErrOccasion{})
+
+	assert.Equal(t, got.Reason(), ReasonForHandler{
+		Name: "a", Password: RedactedValue, Token: RedactedValue, Code: 0,
+	})
+	redactedCause := got.Cause().(Errs)[0]
+	assert.Equal(t, redactedCause.Reason(), &ReasonForHandlerCause{Key: RedactedValue})
+	assert.Equal(t, redactedCause.Cause(), base)
+
+	assert.Equal(t, err.Reason(), ReasonForHandler{Name: "a", Password: "p", Token: 123, Code: 9})
+	assert.Equal(t, cause.Reason(), &ReasonForHandlerCause{Key: "k1"})
+
+	h.Handle(Ok(), ErrOccasion{})
+	assert.True(t, got.IsOk())
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var value interface{}
+	var stack []byte
+	h := RecoverPanic(func(v interface{}, s []byte) {
+		value = v
+		stack = s
+	})(HandlerFunc(func(err Err, occ ErrOccasion) {
+		panic("boom")
+	}))
+
+	assert.NotPanics(t, func() {
+		h.Handle(Err{reason: ReasonForHandler{}}, ErrOccasion{})
+	})
+	assert.Equal(t, value, "boom")
+	assert.Contains(t, string(stack), "TestRecoverPanic")
+
+	h = RecoverPanic(nil)(HandlerFunc(func(err Err, occ ErrOccasion) {
+		panic("boom")
+	}))
+	assert.NotPanics(t, func() {
+		h.Handle(Err{reason: ReasonForHandler{}}, ErrOccasion{})
+	})
+}
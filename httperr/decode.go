@@ -0,0 +1,183 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package httperr
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// RemoteProblem is an error reason which indicates that a server responded
+	// with an error of which problem type is not registered with
+	// RegisterReason function, or without a problem details document.
+	RemoteProblem struct {
+		Type   string
+		Title  string
+		Status int
+		Detail string
+	}
+)
+
+const maxProblemSize = 1 << 20
+
+var (
+	reasonsByType = make(map[string]reflect.Type)
+	reasonsByName = make(map[string]reflect.Type)
+	reasonLock    = sync.RWMutex{}
+)
+
+// RegisterReason is a function which registers the type of the specified
+// reason so that DecodeProblem function can rebuild an Err of the reason from
+// a problem details document.
+// A reason type is looked up by the problem type URI, and then by the reason
+// name in the title member if the URI is not registered.
+// The lookup by the title fails if reason types of the same name in different
+// packages are registered.
+func RegisterReason(reason interface{}) {
+	t := reasonederror.ReasonType(reason)
+	if t == nil {
+		return
+	}
+
+	reasonLock.Lock()
+	defer reasonLock.Unlock()
+
+	reasonsByType[TypeURIPrefix+t.PkgPath()+"#"+t.Name()] = t
+	reasonsByName[t.PkgPath()+"."+t.Name()] = t
+}
+
+// DecodeProblem is a function which reads the body of the specified HTTP
+// response and rebuilds an Err from it.
+//
+// If the status code is less than 400, this function returns Ok without
+// reading nor closing the body, so that the caller can read it.
+// If the body is a problem details document of which type is registered with
+// RegisterReason function, this function returns an Err of the registered
+// reason type, of which fields are filled with the extension members of the
+// same names.
+// Otherwise, this function returns an Err of which reason is RemoteProblem.
+//
+// This function closes the body of the response whenever the status code is
+// 400 or higher.
+func DecodeProblem(resp *http.Response) reasonederror.Err {
+	if resp.StatusCode < 400 {
+		return reasonederror.Ok()
+	}
+
+	defer resp.Body.Close()
+
+	unknown := RemoteProblem{
+		Type:   "about:blank",
+		Title:  http.StatusText(resp.StatusCode),
+		Status: resp.StatusCode,
+	}
+
+	if !isProblem(resp) {
+		return reasonederror.NewErr(unknown)
+	}
+
+	body, e := io.ReadAll(io.LimitReader(resp.Body, maxProblemSize))
+	if e != nil {
+		return reasonederror.NewErr(unknown, e)
+	}
+
+	var members map[string]json.RawMessage
+	if e := json.Unmarshal(body, &members); e != nil {
+		return reasonederror.NewErr(unknown, e)
+	}
+
+	json.Unmarshal(members["type"], &unknown.Type)
+	json.Unmarshal(members["title"], &unknown.Title)
+	json.Unmarshal(members["status"], &unknown.Status)
+	json.Unmarshal(members["detail"], &unknown.Detail)
+
+	t := lookupReason(unknown.Type, unknown.Title)
+	if t == nil {
+		return reasonederror.NewErr(unknown)
+	}
+
+	v := reflect.New(t).Elem()
+
+	n := t.NumField()
+	for i := 0; i < n; i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		raw, ok := members[sf.Name]
+		if !ok {
+			continue
+		}
+		// A field which cannot be decoded, like a redacted field, is left as
+		// its zero value.
+		json.Unmarshal(raw, v.Field(i).Addr().Interface())
+	}
+
+	return reasonederror.NewErr(v.Interface())
+}
+
+func isProblem(resp *http.Response) bool {
+	mt, _, e := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return e == nil && mt == ProblemContentType
+}
+
+func lookupReason(typeURI, title string) reflect.Type {
+	reasonLock.RLock()
+	defer reasonLock.RUnlock()
+
+	if t, ok := reasonsByType[typeURI]; ok {
+		return t
+	}
+
+	var found reflect.Type
+	for _, t := range reasonsByName {
+		if t.Name() != title {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = t
+	}
+	return found
+}
+
+// Transport is an http.RoundTripper which converts error responses with
+// problem details documents into Err(s) with DecodeProblem function.
+// The other responses are returned as they are.
+type Transport struct {
+	// Base is the http.RoundTripper to send requests.
+	// If this is nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// RoundTrip method sends the specified request with the base RoundTripper.
+// If the response has a status code of 400 or more and a problem details
+// document, this method returns an Err rebuilt from the document as the
+// error and no response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, e := base.RoundTrip(req)
+	if e != nil {
+		return resp, e
+	}
+
+	if resp.StatusCode >= 400 && isProblem(resp) {
+		return nil, DecodeProblem(resp)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,315 @@
+package httperr_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/httperr"
+	"github.com/sttk/reasonederror/sqlreasons"
+)
+
+type /* error reasons */ (
+	OrderNotFound struct {
+		OrderID string
+		Items   []int
+	}
+	PaymentDeclined struct {
+		Amount int
+		Card   string `redact:"true"`
+	}
+	NoRows struct {
+		Table string
+	}
+)
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func newProblemServer(err error) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httperr.WriteProblem(w, err)
+	}))
+}
+
+func TestDecodeProblem_registeredReason(t *testing.T) {
+	httperr.RegisterReason(OrderNotFound{})
+	httperr.SetStatus(OrderNotFound{}, http.StatusNotFound)
+
+	srv := newProblemServer(reasonederror.NewErr(OrderNotFound{
+		OrderID: "o1", Items: []int{1, 2},
+	}))
+	defer srv.Close()
+
+	resp, e := http.Get(srv.URL)
+	assert.Nil(t, e)
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case OrderNotFound:
+		assert.Equal(t, r.OrderID, "o1")
+		assert.Equal(t, r.Items, []int{1, 2})
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestDecodeProblem_redactedField(t *testing.T) {
+	httperr.RegisterReason(&PaymentDeclined{})
+
+	srv := newProblemServer(reasonederror.NewErr(PaymentDeclined{
+		Amount: 100, Card: "4111",
+	}))
+	defer srv.Close()
+
+	resp, e := http.Get(srv.URL)
+	assert.Nil(t, e)
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case PaymentDeclined:
+		assert.Equal(t, r.Amount, 100)
+		assert.Equal(t, r.Card, reasonederror.RedactedValue)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestDecodeProblem_lookupByName(t *testing.T) {
+	httperr.RegisterReason(OrderNotFound{})
+
+	resp := &http.Response{
+		StatusCode: 404,
+		Header:     http.Header{"Content-Type": {"application/problem+json; charset=utf-8"}},
+		Body: io.NopCloser(strings.NewReader(
+			`{"type":"https://example.com/probs/order","title":"OrderNotFound","status":404,"OrderID":"o2","Items":"bad"}`)),
+	}
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case OrderNotFound:
+		assert.Equal(t, r.OrderID, "o2")
+		assert.Nil(t, r.Items)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestDecodeProblem_unknownType(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 403,
+		Header:     http.Header{"Content-Type": {"application/problem+json"}},
+		Body: io.NopCloser(strings.NewReader(
+			`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50."}`)),
+	}
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case httperr.RemoteProblem:
+		assert.Equal(t, r.Type, "https://example.com/probs/out-of-credit")
+		assert.Equal(t, r.Title, "You do not have enough credit.")
+		assert.Equal(t, r.Status, 403)
+		assert.Equal(t, r.Detail, "Your current balance is 30, but that costs 50.")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestDecodeProblem_notProblem(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 502,
+		Header:     http.Header{"Content-Type": {"text/html"}},
+		Body:       io.NopCloser(strings.NewReader(`<html></html>`)),
+	}
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case httperr.RemoteProblem:
+		assert.Equal(t, r.Type, "about:blank")
+		assert.Equal(t, r.Title, "Bad Gateway")
+		assert.Equal(t, r.Status, 502)
+		assert.Equal(t, r.Detail, "")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Nil(t, err.Cause())
+}
+
+func TestDecodeProblem_brokenProblem(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 500,
+		Header:     http.Header{"Content-Type": {"application/problem+json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"type":`)),
+	}
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case httperr.RemoteProblem:
+		assert.Equal(t, r.Status, 500)
+		assert.Equal(t, r.Title, "Internal Server Error")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.NotNil(t, err.Cause())
+}
+
+func TestDecodeProblem_success(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(strings.NewReader(`{}`)),
+	}
+
+	err := httperr.DecodeProblem(resp)
+	assert.True(t, err.IsOk())
+}
+
+func TestDecodeProblem_closeBody(t *testing.T) {
+	body := &trackedBody{Reader: strings.NewReader(`ok`)}
+	resp := &http.Response{StatusCode: 200, Body: body}
+
+	err := httperr.DecodeProblem(resp)
+	assert.True(t, err.IsOk())
+	assert.False(t, body.closed)
+	b, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, string(b), "ok")
+
+	body = &trackedBody{Reader: strings.NewReader(`<html></html>`)}
+	resp = &http.Response{
+		StatusCode: 502,
+		Header:     http.Header{"Content-Type": {"text/html"}},
+		Body:       body,
+	}
+
+	err = httperr.DecodeProblem(resp)
+	assert.True(t, err.IsNotOk())
+	assert.True(t, body.closed)
+
+	body = &trackedBody{Reader: strings.NewReader(`{"title":"OrderNotFound"}`)}
+	resp = &http.Response{
+		StatusCode: 404,
+		Header:     http.Header{"Content-Type": {"application/problem+json"}},
+		Body:       body,
+	}
+
+	err = httperr.DecodeProblem(resp)
+	assert.True(t, err.IsNotOk())
+	assert.True(t, body.closed)
+}
+
+func TestDecodeProblem_sameNameInDifferentPackages(t *testing.T) {
+	httperr.RegisterReason(NoRows{})
+	httperr.RegisterReason(sqlreasons.NoRows{})
+
+	srv := newProblemServer(reasonederror.NewErr(NoRows{Table: "orders"}))
+	defer srv.Close()
+
+	resp, e := http.Get(srv.URL)
+	assert.Nil(t, e)
+
+	err := httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case NoRows:
+		assert.Equal(t, r.Table, "orders")
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	srv = newProblemServer(reasonederror.NewErr(sqlreasons.NoRows{Query: "select"}))
+	defer srv.Close()
+
+	resp, e = http.Get(srv.URL)
+	assert.Nil(t, e)
+
+	err = httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case sqlreasons.NoRows:
+		assert.Equal(t, r.Query, "select")
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	resp = &http.Response{
+		StatusCode: 404,
+		Header:     http.Header{"Content-Type": {"application/problem+json"}},
+		Body: io.NopCloser(strings.NewReader(
+			`{"type":"https://example.com/probs/no-rows","title":"NoRows","status":404}`)),
+	}
+
+	err = httperr.DecodeProblem(resp)
+	switch r := err.Reason().(type) {
+	case httperr.RemoteProblem:
+		assert.Equal(t, r.Title, "NoRows")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestTransport(t *testing.T) {
+	httperr.RegisterReason(OrderNotFound{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/problem", func(w http.ResponseWriter, r *http.Request) {
+		httperr.WriteProblem(w, reasonederror.NewErr(OrderNotFound{OrderID: "o3"}))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := &http.Client{Transport: &httperr.Transport{}}
+
+	_, e := client.Get(srv.URL + "/problem")
+	var err reasonederror.Err
+	assert.True(t, errors.As(e, &err))
+	switch r := err.Reason().(type) {
+	case OrderNotFound:
+		assert.Equal(t, r.OrderID, "o3")
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	resp, e := client.Get(srv.URL + "/plain")
+	assert.Nil(t, e)
+	assert.Equal(t, resp.StatusCode, http.StatusNotFound)
+	resp.Body.Close()
+
+	resp, e = client.Get(srv.URL + "/ok")
+	assert.Nil(t, e)
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+	resp.Body.Close()
+}
+
+func TestTransport_baseError(t *testing.T) {
+	client := &http.Client{Transport: &httperr.Transport{
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("refused")
+		}),
+	}}
+
+	_, e := client.Get("http://example.invalid/")
+	assert.NotNil(t, e)
+	var err reasonederror.Err
+	assert.False(t, errors.As(e, &err))
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
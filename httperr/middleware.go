@@ -0,0 +1,65 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package httperr
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// HandlerPanicked is an error reason which indicates that an HTTP handler
+	// panicked.
+	// Value is the recovered value and Stack is the stack trace at the panic,
+	// both of which are redacted.
+	// If the recovered value is an error, it is set as the cause.
+	HandlerPanicked struct {
+		Method string
+		Path   string
+		Value  interface{} `redact:"true"`
+		Stack  string      `redact:"true"`
+	}
+)
+
+// Recoverer is a middleware function which recovers panics in the specified
+// handler, creates an Err of which reason is HandlerPanicked, and writes it
+// with WriteProblem function.
+// Only this Err is notified to the Err creation event handlers for a panic,
+// and its ErrOccasion points to the position where the panic occurred.
+// A panic with http.ErrAbortHandler is re-panicked as it is without
+// notification so that the server aborts the response.
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			reason := HandlerPanicked{
+				Method: r.Method,
+				Path:   r.URL.Path,
+				Value:  v,
+				Stack:  string(debug.Stack()),
+			}
+
+			var err reasonederror.Err
+			if e, ok := v.(error); ok {
+				err = reasonederror.NewErrAtPanic(reason, e)
+			} else {
+				err = reasonederror.NewErrAtPanic(reason)
+			}
+
+			WriteProblem(w, err)
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
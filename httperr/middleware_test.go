@@ -0,0 +1,115 @@
+package httperr_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/httperr"
+)
+
+var (
+	lastErr  reasonederror.Err
+	lastOcc  reasonederror.ErrOccasion
+	errCount int
+)
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastErr = err
+		lastOcc = occ
+		errCount++
+	})
+	reasonederror.SetReasonMessage(FailToSignUp{}, "could not sign up {{.User}} with {{.Password}}")
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+func TestRecoverer_noPanic(t *testing.T) {
+	h := httperr.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, e := http.Get(srv.URL + "/a")
+	assert.Nil(t, e)
+	defer resp.Body.Close()
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+}
+
+func TestRecoverer_occasion(t *testing.T) {
+	var line int
+	h := httperr.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, line, _ = runtime.Caller(0)
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, lastErr.ReasonName(), "HandlerPanicked")
+	assert.Equal(t, lastOcc.File(), "middleware_test.go")
+	assert.Equal(t, lastOcc.Line(), line+1)
+}
+
+func TestRecoverer_panicWithValue(t *testing.T) {
+	h := httperr.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("secret value")
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/users/1", nil))
+
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/problem+json")
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m["title"], "HandlerPanicked")
+	assert.Equal(t, m["Method"], "GET")
+	assert.Equal(t, m["Path"], "/users/1")
+	assert.Equal(t, m["Value"], reasonederror.RedactedValue)
+	assert.Equal(t, m["Stack"], reasonederror.RedactedValue)
+}
+
+func TestRecoverer_panicWithError(t *testing.T) {
+	cause := errors.New("boom")
+
+	h := httperr.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(cause)
+	}))
+
+	n := errCount
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/", nil))
+
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+	assert.Equal(t, errCount-n, 1)
+	assert.Equal(t, lastErr.ReasonName(), "HandlerPanicked")
+	assert.Equal(t, lastErr.Cause(), cause)
+	assert.Equal(t, lastErr.Get("Value"), cause)
+	assert.NotEmpty(t, lastErr.Get("Stack"))
+	assert.True(t, errors.Is(lastErr, cause))
+}
+
+func TestRecoverer_abortHandler(t *testing.T) {
+	h := httperr.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	n := errCount
+
+	rec := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	})
+	assert.Equal(t, errCount, n)
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/sttk/reasonederror"
+)
+
+// ProblemContentType is the media type of a problem details document defined
+// in RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// TypeURIPrefix is the prefix of problem type URIs.
+// A problem type URI is this prefix followed by the package path and the name
+// of a reason type, like "https://pkg.go.dev/github.com/foo/bar#FailToRead",
+// which refers to the documentation of the reason type.
+const TypeURIPrefix = "https://pkg.go.dev/"
+
+var reservedMembers = map[string]bool{
+	"type":     true,
+	"title":    true,
+	"status":   true,
+	"detail":   true,
+	"instance": true,
+	"errors":   true,
+}
+
+// TypeURI is a function which returns a problem type URI of the specified Err.
+// If the Err is Ok, this function returns "about:blank".
+func TypeURI(err reasonederror.Err) string {
+	if err.IsOk() {
+		return "about:blank"
+	}
+	return TypeURIPrefix + err.ReasonPackage() + "#" + err.ReasonName()
+}
+
+// WriteProblem is a function which writes the specified error as a problem
+// details document of RFC 7807 with "application/problem+json" content type.
+//
+// If the error is or wraps a reasonederror.Err, the members of the document
+// are as follows:
+//
+//   - type: the URI returned by TypeURI function,
+//   - title: the name of the reason type,
+//   - status: the status code returned by Status function,
+//   - detail: the result of RedactedMessage method if it is not empty,
+//   - errors: if the cause of the Err is a reasonederror.Errs, like the Err
+//     returned by validate.Struct function, an array of objects which have
+//     the type, title and detail members and the redacted situation of each
+//     Err,
+//   - the others: the redacted situation of the Err as extension members.
+//
+// Otherwise, this function writes a document of "about:blank" type with 500
+// Internal Server Error, which contains no information of the error.
+// If the error is nil or Ok, this function writes nothing.
+func WriteProblem(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+
+	var doc map[string]interface{}
+	var status int
+
+	var re reasonederror.Err
+	if errors.As(err, &re) {
+		if re.IsOk() {
+			return
+		}
+		status = errStatus(re)
+		doc = problemMembers(re, status)
+	} else {
+		status = http.StatusInternalServerError
+		doc = map[string]interface{}{
+			"type":   "about:blank",
+			"title":  http.StatusText(status),
+			"status": status,
+		}
+	}
+
+	b, e := json.Marshal(doc)
+	if e != nil {
+		b = []byte(`{"type":"about:blank","status":500}`)
+		status = http.StatusInternalServerError
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", ProblemContentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func problemMembers(err reasonederror.Err, status int) map[string]interface{} {
+	doc := situationMembers(err)
+
+	doc["type"] = TypeURI(err)
+	doc["title"] = err.ReasonName()
+	doc["status"] = status
+
+	if msg := err.RedactedMessage(); msg != "" {
+		doc["detail"] = msg
+	}
+
+	if errs, ok := err.Cause().(reasonederror.Errs); ok {
+		a := make([]map[string]interface{}, 0, len(errs))
+		for _, e := range errs {
+			m := situationMembers(e)
+			m["type"] = TypeURI(e)
+			m["title"] = e.ReasonName()
+			if msg := e.RedactedMessage(); msg != "" {
+				m["detail"] = msg
+			}
+			a = append(a, m)
+		}
+		doc["errors"] = a
+	}
+
+	return doc
+}
+
+func situationMembers(err reasonederror.Err) map[string]interface{} {
+	m := make(map[string]interface{})
+
+	for k, v := range err.RedactedSituation() {
+		if reservedMembers[k] {
+			continue
+		}
+		b, e := json.Marshal(v)
+		if e != nil {
+			continue
+		}
+		m[k] = json.RawMessage(b)
+	}
+
+	return m
+}
@@ -0,0 +1,209 @@
+package httperr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/httperr"
+	"github.com/sttk/reasonederror/validate"
+)
+
+type /* error reasons */ (
+	ReasonWithReservedNames struct {
+		Type   string
+		status int
+		Func   func()
+		Count  int
+	}
+	FailToSignUp struct {
+		User     string
+		Password string `redact:"true"`
+	}
+	FailToChangePassword struct {
+		User     string
+		Password string `redact:"true"`
+	}
+)
+
+func (r FailToChangePassword) Message() string {
+	return "could not change the password of " + r.User + " to " + r.Password
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &m))
+	return m
+}
+
+func TestTypeURI(t *testing.T) {
+	err := reasonederror.NewErr(UserNotFound{})
+	assert.Equal(t, httperr.TypeURI(err),
+		"https://pkg.go.dev/github.com/sttk/reasonederror/httperr_test#UserNotFound")
+
+	assert.Equal(t, httperr.TypeURI(reasonederror.Ok()), "about:blank")
+}
+
+func TestWriteProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, reasonederror.NewErr(UserNotFound{UserID: "u1"}))
+
+	assert.Equal(t, rec.Code, http.StatusNotFound)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/problem+json")
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m, map[string]interface{}{
+		"type":   "https://pkg.go.dev/github.com/sttk/reasonederror/httperr_test#UserNotFound",
+		"title":  "UserNotFound",
+		"status": float64(404),
+		"UserID": "u1",
+	})
+}
+
+func TestWriteProblem_withMessage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, reasonederror.NewErr(InvalidUserName{Name: "?"}))
+
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m["title"], "InvalidUserName")
+	assert.Equal(t, m["detail"], "the user name is invalid")
+	assert.Equal(t, m["Name"], "?")
+}
+
+func TestWriteProblem_redactedSituation(t *testing.T) {
+	cause := reasonederror.NewErr(UserNotFound{UserID: "u1"})
+	err := reasonederror.NewErr(FailToQueryDB{
+		Query: "select", Password: "secret",
+	}, cause)
+
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, err)
+
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m["title"], "FailToQueryDB")
+	assert.Equal(t, m["Query"], "select")
+	assert.Equal(t, m["Password"], reasonederror.RedactedValue)
+	assert.Equal(t, m["UserID"], "u1")
+	assert.Nil(t, m["detail"])
+}
+
+func TestWriteProblem_redactedMessage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, reasonederror.NewErr(FailToSignUp{
+		User: "foo", Password: "secret",
+	}))
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m["detail"], "could not sign up foo with [REDACTED]")
+	assert.Equal(t, m["Password"], reasonederror.RedactedValue)
+
+	rec = httptest.NewRecorder()
+	httperr.WriteProblem(rec, reasonederror.NewErr(FailToChangePassword{
+		User: "foo", Password: "secret",
+	}))
+
+	m = decodeBody(t, rec)
+	assert.Equal(t, m["detail"], "FailToChangePassword")
+	assert.NotContains(t, rec.Body.String(), "secret")
+}
+
+func TestWriteProblem_reservedAndUnmarshalableMembers(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, reasonederror.NewErr(ReasonWithReservedNames{
+		Type: "x", status: 1, Func: func() {}, Count: 2,
+	}))
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m, map[string]interface{}{
+		"type":   "https://pkg.go.dev/github.com/sttk/reasonederror/httperr_test#ReasonWithReservedNames",
+		"title":  "ReasonWithReservedNames",
+		"status": float64(500),
+		"Type":   "x",
+		"Count":  float64(2),
+	})
+}
+
+func TestWriteProblem_notErr(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, errors.New("internal detail"))
+
+	assert.Equal(t, rec.Code, http.StatusInternalServerError)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/problem+json")
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m, map[string]interface{}{
+		"type":   "about:blank",
+		"title":  "Internal Server Error",
+		"status": float64(500),
+	})
+}
+
+func TestWriteProblem_nilOrOk(t *testing.T) {
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, nil)
+	httperr.WriteProblem(rec, reasonederror.Ok())
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Body.Len(), 0)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "")
+}
+
+func TestWriteProblem_errs(t *testing.T) {
+	errs := reasonederror.Errs{
+		reasonederror.NewErr(InvalidUserName{Name: "?"}),
+		reasonederror.NewErr(UserNotFound{UserID: "u1"}),
+	}
+	err := reasonederror.NewErr(InvalidUserName{Name: "x"}, errs)
+
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, err)
+
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m["title"], "InvalidUserName")
+	assert.Equal(t, m["errors"], []interface{}{
+		map[string]interface{}{
+			"type":   "https://pkg.go.dev/github.com/sttk/reasonederror/httperr_test#InvalidUserName",
+			"title":  "InvalidUserName",
+			"detail": "the user name is invalid",
+			"Name":   "?",
+		},
+		map[string]interface{}{
+			"type":   "https://pkg.go.dev/github.com/sttk/reasonederror/httperr_test#UserNotFound",
+			"title":  "UserNotFound",
+			"UserID": "u1",
+		},
+	})
+}
+
+func TestWriteProblem_validation(t *testing.T) {
+	type Form struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	rec := httptest.NewRecorder()
+	httperr.WriteProblem(rec, validate.Struct(Form{}))
+
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+
+	m := decodeBody(t, rec)
+	assert.Equal(t, m["title"], "InvalidFields")
+	assert.Equal(t, m["Count"], float64(1))
+	assert.Equal(t, m["errors"], []interface{}{
+		map[string]interface{}{
+			"type":   "https://pkg.go.dev/github.com/sttk/reasonederror/validate#Required",
+			"title":  "Required",
+			"detail": "name is required",
+			"Field":  "name",
+		},
+	})
+}
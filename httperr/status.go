@@ -0,0 +1,92 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package httperr provides functions to translate reasonederror.Err(s) into
+// HTTP responses of RFC 7807 problem details, and to rebuild Err(s) from them.
+package httperr
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/sttk/reasonederror"
+)
+
+// httpStatuser is an interface for reason structs which declare their HTTP
+// status codes by themselves.
+type httpStatuser interface {
+	HTTPStatus() int
+}
+
+var (
+	statuses   = make(map[reflect.Type]int)
+	statusLock = sync.RWMutex{}
+)
+
+// SetStatus is a function which registers an HTTP status code for the type of
+// the specified reason.
+// If a reason struct has HTTPStatus method, its result takes precedence over
+// the registered status code.
+func SetStatus(reason interface{}, status int) {
+	t := reasonederror.ReasonType(reason)
+	if t == nil {
+		return
+	}
+
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	statuses[t] = status
+}
+
+// Status is a function which returns an HTTP status code for the specified
+// error.
+// If the error is or wraps a reasonederror.Err, the status code is solved in
+// the following order:
+//
+//  1. the result of HTTPStatus method of the reason struct,
+//  2. the status code registered with SetStatus function,
+//  3. 400 Bad Request if the Err is a user error,
+//  4. 500 Internal Server Error.
+//
+// If the error is nil or Ok, this function returns 200 OK, and if the error is
+// not a reasonederror.Err, this function returns 500 Internal Server Error.
+func Status(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var re reasonederror.Err
+	if !errors.As(err, &re) {
+		return http.StatusInternalServerError
+	}
+
+	return errStatus(re)
+}
+
+func errStatus(err reasonederror.Err) int {
+	if err.IsOk() {
+		return http.StatusOK
+	}
+
+	if s, ok := err.Reason().(httpStatuser); ok {
+		return s.HTTPStatus()
+	}
+
+	statusLock.RLock()
+	status, ok := statuses[reasonederror.ReasonType(err.Reason())]
+	statusLock.RUnlock()
+
+	if ok {
+		return status
+	}
+
+	if err.IsUserError() {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,66 @@
+package httperr_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/httperr"
+)
+
+type /* error reasons */ (
+	UserNotFound struct {
+		UserID string
+	}
+	InvalidUserName struct {
+		Name string
+	}
+	TooManyRequests struct{}
+	FailToQueryDB   struct {
+		Query    string
+		Password string `redact:"true"`
+	}
+)
+
+func (r UserNotFound) HTTPStatus() int {
+	return http.StatusNotFound
+}
+
+func (r InvalidUserName) Class() reasonederror.Class {
+	return reasonederror.ClassUserError
+}
+
+func (r InvalidUserName) Message() string {
+	return "the user name is invalid"
+}
+
+func TestStatus(t *testing.T) {
+	httperr.SetStatus(TooManyRequests{}, http.StatusTooManyRequests)
+	httperr.SetStatus(UserNotFound{}, http.StatusGone)
+	httperr.SetStatus(nil, http.StatusTeapot)
+
+	assert.Equal(t, httperr.Status(nil), http.StatusOK)
+	assert.Equal(t, httperr.Status(reasonederror.Ok()), http.StatusOK)
+	assert.Equal(t, httperr.Status(errors.New("x")), http.StatusInternalServerError)
+
+	err := reasonederror.NewErr(UserNotFound{UserID: "u1"})
+	assert.Equal(t, httperr.Status(err), http.StatusNotFound)
+
+	err = reasonederror.NewErr(&UserNotFound{UserID: "u1"})
+	assert.Equal(t, httperr.Status(err), http.StatusNotFound)
+
+	err = reasonederror.NewErr(InvalidUserName{Name: "?"})
+	assert.Equal(t, httperr.Status(err), http.StatusBadRequest)
+
+	err = reasonederror.NewErr(&TooManyRequests{})
+	assert.Equal(t, httperr.Status(err), http.StatusTooManyRequests)
+
+	err = reasonederror.NewErr(FailToQueryDB{})
+	assert.Equal(t, httperr.Status(err), http.StatusInternalServerError)
+
+	wrapped := fmt.Errorf("wrapped: %w", reasonederror.NewErr(TooManyRequests{}))
+	assert.Equal(t, httperr.Status(wrapped), http.StatusTooManyRequests)
+}
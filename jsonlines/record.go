@@ -0,0 +1,121 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package jsonlines
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+// Record is a struct which is written as a line of a JSON Lines file for an
+// Err notification.
+// Situation is the redacted situation of the Err, and Attrs is the attributes
+// of the ErrOccasion. The values which cannot be marshaled into JSON are
+// omitted.
+type Record struct {
+	Time        time.Time                  `json:"time"`
+	File        string                     `json:"file"`
+	Line        int                        `json:"line"`
+	Severity    string                     `json:"severity"`
+	Reason      string                     `json:"reason"`
+	Package     string                     `json:"package"`
+	Situation   map[string]json.RawMessage `json:"situation,omitempty"`
+	Causes      []Cause                    `json:"causes,omitempty"`
+	Fingerprint string                     `json:"fingerprint"`
+	Attrs       map[string]json.RawMessage `json:"attrs,omitempty"`
+}
+
+// Cause is a struct which represents an error in the cause chain of an Err.
+// If the error is an Err, Reason and Package are set.
+// If the error is an Errs, Errs is set to the cause chains of its elements,
+// each of which starts with the element itself, and the chain ends with it.
+// Otherwise, Error is set to the text of the error and the chain ends with it.
+// The texts of Err(s) are never set to Error, because they contain the values
+// of redacted fields.
+type Cause struct {
+	Reason  string    `json:"reason,omitempty"`
+	Package string    `json:"package,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Errs    [][]Cause `json:"errs,omitempty"`
+}
+
+// NewRecord is a function which creates a Record from the specified Err and
+// ErrOccasion.
+func NewRecord(err reasonederror.Err, occ reasonederror.ErrOccasion) Record {
+	rec := Record{
+		Time:        occ.Time(),
+		File:        occ.File(),
+		Line:        occ.Line(),
+		Severity:    err.Severity().String(),
+		Reason:      err.ReasonName(),
+		Package:     err.ReasonPackage(),
+		Fingerprint: err.Fingerprint(),
+	}
+
+	rec.Situation = rawMessages(err.RedactedSituation())
+	rec.Attrs = rawMessages(occ.Attrs())
+
+	rec.Causes = causeChain(err.Cause())
+
+	return rec
+}
+
+func causeChain(cause error) []Cause {
+	var chain []Cause
+
+	for cause != nil {
+		switch e := cause.(type) {
+		case reasonederror.Err:
+			chain = append(chain, Cause{
+				Reason:  e.ReasonName(),
+				Package: e.ReasonPackage(),
+			})
+			cause = e.Cause()
+		case reasonederror.Errs:
+			a := make([][]Cause, 0, len(e))
+			for _, x := range e {
+				a = append(a, causeChain(x))
+			}
+			return append(chain, Cause{Errs: a})
+		default:
+			// The text of an error which wraps an Err contains the text of
+			// the Err, so it is skipped and the wrapped error is followed.
+			if wrapsErr(cause) {
+				cause = errors.Unwrap(cause)
+				continue
+			}
+			return append(chain, Cause{Error: cause.Error()})
+		}
+	}
+
+	return chain
+}
+
+func wrapsErr(err error) bool {
+	var e reasonederror.Err
+	if errors.As(err, &e) {
+		return true
+	}
+	var errs reasonederror.Errs
+	return errors.As(err, &errs)
+}
+
+func rawMessages(m map[string]interface{}) map[string]json.RawMessage {
+	var raw map[string]json.RawMessage
+	for k, v := range m {
+		b, e := json.Marshal(v)
+		if e != nil {
+			continue
+		}
+		if raw == nil {
+			raw = make(map[string]json.RawMessage)
+		}
+		raw[k] = b
+	}
+	return raw
+}
@@ -0,0 +1,109 @@
+package jsonlines_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/jsonlines"
+)
+
+type /* error reasons */ (
+	FailToSendMail struct {
+		To       string
+		Password string `redact:"true"`
+		Callback func()
+	}
+	FailToConnect struct {
+		Host string
+	}
+)
+
+var lastOcc reasonederror.ErrOccasion
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastOcc = occ
+	})
+	reasonederror.SetErrOccasionAttrs(map[string]interface{}{
+		"service":  "mailer",
+		"callback": func() {},
+	})
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+func TestNewRecord(t *testing.T) {
+	cause := reasonederror.NewErr(FailToConnect{Host: "smtp"}, errors.New("refused"))
+	err := reasonederror.NewErr(FailToSendMail{To: "a@b.c", Password: "secret"}, cause)
+	occ := lastOcc
+
+	rec := jsonlines.NewRecord(err, occ)
+	assert.Equal(t, rec.Time, occ.Time())
+	assert.Equal(t, rec.File, occ.File())
+	assert.Equal(t, rec.Line, occ.Line())
+	assert.Equal(t, rec.Severity, "error")
+	assert.Equal(t, rec.Reason, "FailToSendMail")
+	assert.Equal(t, rec.Package, "github.com/sttk/reasonederror/jsonlines_test")
+	assert.Equal(t, rec.Fingerprint, err.Fingerprint())
+	assert.Equal(t, rec.Situation, map[string]json.RawMessage{
+		"To":       json.RawMessage(`"a@b.c"`),
+		"Password": json.RawMessage(`"[REDACTED]"`),
+		"Host":     json.RawMessage(`"smtp"`),
+	})
+	assert.Equal(t, rec.Causes, []jsonlines.Cause{
+		{Reason: "FailToConnect", Package: "github.com/sttk/reasonederror/jsonlines_test"},
+		{Error: "refused"},
+	})
+	assert.Equal(t, rec.Attrs, map[string]json.RawMessage{
+		"service": json.RawMessage(`"mailer"`),
+	})
+}
+
+func TestNewRecord_errsCause(t *testing.T) {
+	errs := reasonederror.Errs{
+		reasonederror.NewErr(FailToSendMail{To: "a@b.c", Password: "secret"},
+			reasonederror.NewErr(FailToConnect{Host: "smtp"}, errors.New("refused"))),
+		reasonederror.NewErr(FailToConnect{Host: "imap"}),
+	}
+	err := reasonederror.NewErr(FailToConnect{Host: "x"}, fmt.Errorf("wrapped: %w", errs))
+
+	rec := jsonlines.NewRecord(err, lastOcc)
+	pkg := "github.com/sttk/reasonederror/jsonlines_test"
+	assert.Equal(t, rec.Causes, []jsonlines.Cause{
+		{Errs: [][]jsonlines.Cause{
+			{
+				{Reason: "FailToSendMail", Package: pkg},
+				{Reason: "FailToConnect", Package: pkg},
+				{Error: "refused"},
+			},
+			{
+				{Reason: "FailToConnect", Package: pkg},
+			},
+		}},
+	})
+
+	b, e := json.Marshal(rec)
+	assert.Nil(t, e)
+	assert.False(t, strings.Contains(string(b), "secret"), string(b))
+}
+
+func TestNewRecord_noSituationAndCause(t *testing.T) {
+	type NoField struct{}
+	rec := jsonlines.NewRecord(reasonederror.NewErr(NoField{}), lastOcc)
+
+	b, e := json.Marshal(rec)
+	assert.Nil(t, e)
+
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(b, &m))
+	assert.Equal(t, m["reason"], "NoField")
+	assert.NotContains(t, m, "situation")
+	assert.NotContains(t, m, "causes")
+}
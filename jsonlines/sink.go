@@ -0,0 +1,352 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package jsonlines provides an Err notification handler which appends a JSON
+// object per notification to a file in JSON Lines format, with rotation of
+// the file.
+//
+//	sink, err := jsonlines.NewSink("/var/log/app/errors.jsonl", jsonlines.Options{
+//	    MaxSize:      10 << 20,
+//	    MaxAge:       24 * time.Hour,
+//	    MaxBackups:   7,
+//	    SyncInterval: time.Second,
+//	})
+//	if err.IsNotOk() {
+//	    return err
+//	}
+//	defer sink.Close()
+//
+//	reasonederror.AddAsyncErrHandler(sink.Handle)
+//	reasonederror.FixErrCfgs()
+package jsonlines
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// FailToOpenFile is an error reason which indicates that a file of a sink
+	// could not be opened.
+	FailToOpenFile struct {
+		Path string
+	}
+
+	// FailToWriteFile is an error reason which indicates that a sink failed
+	// to write records, to rotate or to sync the file.
+	// The cause is the first error which occurred.
+	FailToWriteFile struct {
+		Path string
+	}
+
+	// FailToCloseFile is an error reason which indicates that a file of a sink
+	// could not be closed.
+	FailToCloseFile struct {
+		Path string
+	}
+)
+
+// Options is a struct which configures a Sink.
+type Options struct {
+	// MaxSize is the maximum size of a file in bytes.
+	// If writing a record makes a file larger than this, the file is rotated
+	// before writing. If this is zero or less, a file is not rotated by size.
+	MaxSize int64
+
+	// MaxAge is the maximum time since a file was opened.
+	// If this is zero or less, a file is not rotated by age.
+	MaxAge time.Duration
+
+	// MaxBackups is the number of rotated files to keep.
+	// Rotated files are compressed with gzip, and the oldest ones over this
+	// number are removed. If this is zero or less, all rotated files are kept.
+	MaxBackups int
+
+	// SyncInterval is the interval to sync a file to the storage.
+	// If this is zero or less, a file is synced only when it is rotated or
+	// closed.
+	SyncInterval time.Duration
+
+	// Now is the function which returns the current time, used for the age
+	// and the names of rotated files.
+	// If this is nil, time.Now is used.
+	Now func() time.Time
+}
+
+const backupTimeFormat = "20060102T150405.000000000"
+
+// Sink is a struct which writes Err notifications to a file in JSON Lines
+// format.
+// The methods of Sink are safe for concurrent use.
+type Sink struct {
+	path string
+	opts Options
+
+	mutex    sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	closed   bool
+	firstErr error
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSink is a function which creates a Sink which appends records to the file
+// of the specified path.
+// If the file exists, records are appended to it, otherwise it is created.
+func NewSink(path string, opts Options) (*Sink, reasonederror.Err) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	s := &Sink{path: path, opts: opts, done: make(chan struct{})}
+	if e := s.open(); e != nil {
+		return nil, reasonederror.NewErr(FailToOpenFile{Path: path}, e)
+	}
+
+	if opts.SyncInterval > 0 {
+		s.wg.Add(1)
+		go s.syncPeriodically()
+	}
+
+	return s, reasonederror.Ok()
+}
+
+// Handle method writes a record of the specified Err and ErrOccasion.
+// This method is used as an Err notification handler.
+// If writing fails, the record is dropped and the error is returned by Close
+// method, because creating an Err here would cause another notification.
+func (s *Sink) Handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	b, e := json.Marshal(NewRecord(err, occ))
+	if e != nil {
+		return
+	}
+	b = append(b, '\n')
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	if s.file == nil || s.shouldRotate(int64(len(b))) {
+		if e := s.rotate(); e != nil {
+			s.setErr(e)
+			return
+		}
+	}
+
+	n, e := s.file.Write(b)
+	s.size += int64(n)
+	if e != nil {
+		s.setErr(e)
+	}
+}
+
+// Close method stops this Sink, and syncs and closes the file.
+// After this method is called, Handle method does nothing.
+// If writing records failed, this method returns an Err of which reason is
+// FailToWriteFile.
+func (s *Sink) Close() reasonederror.Err {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return reasonederror.Ok()
+	}
+	s.closed = true
+	s.mutex.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.file != nil {
+		if e := s.file.Sync(); e != nil {
+			s.setErr(e)
+		}
+		if e := s.file.Close(); e != nil {
+			return reasonederror.NewErr(FailToCloseFile{Path: s.path}, e)
+		}
+		s.file = nil
+	}
+
+	if s.firstErr != nil {
+		return reasonederror.NewErr(FailToWriteFile{Path: s.path}, s.firstErr)
+	}
+	return reasonederror.Ok()
+}
+
+func (s *Sink) open() error {
+	f, e := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if e != nil {
+		return e
+	}
+
+	fi, e := f.Stat()
+	if e != nil {
+		f.Close()
+		return e
+	}
+
+	s.file = f
+	s.size = fi.Size()
+	s.openedAt = s.opts.Now()
+	return nil
+}
+
+func (s *Sink) shouldRotate(n int64) bool {
+	if s.opts.MaxSize > 0 && s.size > 0 && s.size+n > s.opts.MaxSize {
+		return true
+	}
+	if s.opts.MaxAge > 0 && s.opts.Now().Sub(s.openedAt) >= s.opts.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (s *Sink) rotate() error {
+	if s.file != nil {
+		s.file.Sync()
+		e := s.file.Close()
+		s.file = nil
+		if e != nil {
+			return e
+		}
+
+		if s.size > 0 {
+			if e := s.backup(); e != nil {
+				return e
+			}
+		}
+	}
+
+	return s.open()
+}
+
+// backup renames the current file with a timestamp, compresses it, and
+// removes old backups.
+func (s *Sink) backup() error {
+	ext := filepath.Ext(s.path)
+	prefix := strings.TrimSuffix(s.path, ext) + "-"
+	name := prefix + s.opts.Now().UTC().Format(backupTimeFormat) + ext
+
+	if e := os.Rename(s.path, name); e != nil {
+		return e
+	}
+	if e := compress(name); e != nil {
+		return e
+	}
+
+	if s.opts.MaxBackups <= 0 {
+		return nil
+	}
+
+	backups, e := listBackups(prefix, ext+".gz")
+	if e != nil {
+		return e
+	}
+	for len(backups) > s.opts.MaxBackups {
+		if e := os.Remove(backups[0]); e != nil {
+			return e
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+func compress(name string) error {
+	src, e := os.Open(name)
+	if e != nil {
+		return e
+	}
+	defer src.Close()
+
+	dst, e := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if e != nil {
+		return e
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, e = io.Copy(zw, src)
+	if e == nil {
+		e = zw.Close()
+	}
+	if e == nil {
+		e = dst.Sync()
+	}
+	if e2 := dst.Close(); e == nil {
+		e = e2
+	}
+	if e != nil {
+		os.Remove(name + ".gz")
+		return e
+	}
+
+	return os.Remove(name)
+}
+
+// listBackups returns the paths of the backup files sorted from the oldest.
+func listBackups(prefix, suffix string) ([]string, error) {
+	dir := filepath.Dir(prefix)
+	base := filepath.Base(prefix)
+
+	entries, e := os.ReadDir(dir)
+	if e != nil {
+		return nil, e
+	}
+
+	var backups []string
+	for _, ent := range entries {
+		name := ent.Name()
+		if ent.Type().IsRegular() &&
+			strings.HasPrefix(name, base) && strings.HasSuffix(name, suffix) &&
+			len(name) == len(base)+len(backupTimeFormat)+len(suffix) {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+func (s *Sink) syncPeriodically() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.opts.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mutex.Lock()
+			if s.file != nil {
+				if e := s.file.Sync(); e != nil {
+					s.setErr(e)
+				}
+			}
+			s.mutex.Unlock()
+		}
+	}
+}
+
+func (s *Sink) setErr(e error) {
+	if s.firstErr == nil {
+		s.firstErr = e
+	}
+}
@@ -0,0 +1,231 @@
+package jsonlines_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/jsonlines"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func readLines(t *testing.T, r io.Reader) []jsonlines.Record {
+	var recs []jsonlines.Record
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		var rec jsonlines.Record
+		assert.Nil(t, json.Unmarshal(sc.Bytes(), &rec))
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func readFile(t *testing.T, path string) []jsonlines.Record {
+	f, e := os.Open(path)
+	assert.Nil(t, e)
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, e := gzip.NewReader(f)
+		assert.Nil(t, e)
+		r = zr
+	}
+	return readLines(t, r)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	entries, e := os.ReadDir(dir)
+	assert.Nil(t, e)
+	var names []string
+	for _, ent := range entries {
+		names = append(names, ent.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSink_Handle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+	sink, err := jsonlines.NewSink(path, jsonlines.Options{})
+	assert.True(t, err.IsOk())
+
+	e1 := reasonederror.NewErr(FailToConnect{Host: "a"})
+	o1 := lastOcc
+	e2 := reasonederror.NewErr(FailToSendMail{To: "x", Password: "p"}, e1)
+	o2 := lastOcc
+	sink.Handle(e1, o1)
+	sink.Handle(e2, o2)
+
+	assert.True(t, sink.Close().IsOk())
+
+	recs := readFile(t, path)
+	assert.Equal(t, len(recs), 2)
+	assert.Equal(t, recs[0].Reason, "FailToConnect")
+	assert.Equal(t, recs[0].Line, o1.Line())
+	assert.True(t, recs[0].Time.Equal(o1.Time()))
+	assert.Equal(t, recs[1].Reason, "FailToSendMail")
+	assert.Equal(t, string(recs[1].Situation["Password"]), `"[REDACTED]"`)
+	assert.Equal(t, recs[1].Causes[0].Reason, "FailToConnect")
+
+	sink.Handle(e1, o1)
+	assert.Equal(t, len(readFile(t, path)), 2)
+	assert.True(t, sink.Close().IsOk())
+}
+
+func TestSink_appendToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+	for i := 0; i < 2; i++ {
+		sink, err := jsonlines.NewSink(path, jsonlines.Options{})
+		assert.True(t, err.IsOk())
+		sink.Handle(reasonederror.NewErr(FailToConnect{Host: "a"}), lastOcc)
+		assert.True(t, sink.Close().IsOk())
+	}
+
+	assert.Equal(t, len(readFile(t, path)), 2)
+}
+
+func TestSink_rotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errors.jsonl")
+	clock := &fakeClock{now: time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)}
+
+	err := reasonederror.NewErr(FailToConnect{Host: "a"})
+	occ := lastOcc
+	b, _ := json.Marshal(jsonlines.NewRecord(err, occ))
+	lineSize := int64(len(b) + 1)
+
+	sink, e := jsonlines.NewSink(path, jsonlines.Options{
+		MaxSize:    lineSize * 2,
+		MaxBackups: 2,
+		Now:        clock.Now,
+	})
+	assert.True(t, e.IsOk())
+
+	for i := 0; i < 7; i++ {
+		sink.Handle(err, occ)
+		clock.now = clock.now.Add(time.Second)
+	}
+	assert.True(t, sink.Close().IsOk())
+
+	assert.Equal(t, listDir(t, dir), []string{
+		"errors-20230401T120004.000000000.jsonl.gz",
+		"errors-20230401T120006.000000000.jsonl.gz",
+		"errors.jsonl",
+	})
+	assert.Equal(t, len(readFile(t, filepath.Join(dir, "errors-20230401T120004.000000000.jsonl.gz"))), 2)
+	assert.Equal(t, len(readFile(t, filepath.Join(dir, "errors-20230401T120006.000000000.jsonl.gz"))), 2)
+	assert.Equal(t, len(readFile(t, path)), 1)
+}
+
+func TestSink_rotateByAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errors.jsonl")
+	clock := &fakeClock{now: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)}
+
+	sink, e := jsonlines.NewSink(path, jsonlines.Options{
+		MaxAge: time.Hour,
+		Now:    clock.Now,
+	})
+	assert.True(t, e.IsOk())
+
+	err := reasonederror.NewErr(FailToConnect{Host: "a"})
+	occ := lastOcc
+
+	sink.Handle(err, occ)
+	clock.now = clock.now.Add(30 * time.Minute)
+	sink.Handle(err, occ)
+	clock.now = clock.now.Add(30 * time.Minute)
+	sink.Handle(err, occ)
+	clock.now = clock.now.Add(3 * time.Hour)
+	sink.Handle(err, occ)
+	assert.True(t, sink.Close().IsOk())
+
+	assert.Equal(t, listDir(t, dir), []string{
+		"errors-20230401T010000.000000000.jsonl.gz",
+		"errors-20230401T040000.000000000.jsonl.gz",
+		"errors.jsonl",
+	})
+	assert.Equal(t, len(readFile(t, filepath.Join(dir, "errors-20230401T010000.000000000.jsonl.gz"))), 2)
+	assert.Equal(t, len(readFile(t, filepath.Join(dir, "errors-20230401T040000.000000000.jsonl.gz"))), 1)
+	assert.Equal(t, len(readFile(t, path)), 1)
+}
+
+func TestSink_syncInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+
+	sink, e := jsonlines.NewSink(path, jsonlines.Options{SyncInterval: time.Millisecond})
+	assert.True(t, e.IsOk())
+
+	err := reasonederror.NewErr(FailToConnect{Host: "a"})
+	occ := lastOcc
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sink.Handle(err, occ)
+			time.Sleep(time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	assert.True(t, sink.Close().IsOk())
+
+	assert.Equal(t, len(readFile(t, path)), 20)
+}
+
+func TestNewSink_failToOpenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "no", "such", "dir", "errors.jsonl")
+
+	sink, err := jsonlines.NewSink(path, jsonlines.Options{})
+	assert.Nil(t, sink)
+	switch r := err.Reason().(type) {
+	case jsonlines.FailToOpenFile:
+		assert.Equal(t, r.Path, path)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, os.IsNotExist(err.Cause()))
+}
+
+func TestSink_failToWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "errors.jsonl")
+
+	sink, e := jsonlines.NewSink(path, jsonlines.Options{MaxSize: 1})
+	assert.True(t, e.IsOk())
+
+	sink.Handle(reasonederror.NewErr(FailToConnect{Host: "a"}), lastOcc)
+	assert.Nil(t, os.Remove(dir+"/errors.jsonl"))
+	assert.Nil(t, os.Mkdir(dir+"/errors.jsonl", 0755))
+	sink.Handle(reasonederror.NewErr(FailToConnect{Host: "b"}), lastOcc)
+
+	err := sink.Close()
+	switch r := err.Reason().(type) {
+	case jsonlines.FailToWriteFile:
+		assert.Equal(t, r.Path, path)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.NotNil(t, err.Cause())
+}
@@ -0,0 +1,131 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+type /* error reasons */ (
+	// FailToParseMessageTemplate is an error reason which indicates that a
+	// message template registered for a reason cannot be parsed.
+	FailToParseMessageTemplate struct {
+		Reason   string
+		Template string
+	}
+)
+
+var reasonMessages = make(map[reflect.Type]*template.Template)
+
+// messager is an interface for reason structs which provide human-readable
+// texts by themselves.
+type messager interface {
+	Message() string
+}
+
+// SetReasonMessage is a function which registers a text/template string as
+// a message for the type of the specified reason.
+// The template is executed with the situation map of an Err, so fields of the
+// reason struct and of its causal Errs can be referred like "{{.Name}}".
+// This function is effective only before calling FixErrCfgs function.
+func SetReasonMessage(reason interface{}, text string) Err {
+	t := reasonType(reason)
+	if t == nil {
+		return ok
+	}
+
+	tmpl, e := template.New(t.String()).Parse(text)
+	if e != nil {
+		return NewErr(FailToParseMessageTemplate{
+			Reason:   t.Name(),
+			Template: text,
+		}, e)
+	}
+
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return ok
+	}
+
+	reasonMessages[t] = tmpl
+	return ok
+}
+
+// Message method returns a human-readable text of this Err.
+// If the reason struct has Message method, this method returns its result.
+// Otherwise, if a message template is registered for the reason type with
+// SetReasonMessage, this method returns the text rendered by the template.
+// If neither is available or the rendering fails, this method returns the
+// same string as Error method.
+func (err Err) Message() string {
+	if err.reason == nil {
+		return ""
+	}
+
+	if m, ok := err.reason.(messager); ok {
+		return m.Message()
+	}
+
+	tmpl, ok := reasonMessages[reasonType(err.reason)]
+	if ok {
+		var b strings.Builder
+		if e := tmpl.Execute(&b, err.Situation()); e == nil {
+			return b.String()
+		}
+	}
+
+	return err.Error()
+}
+
+// RedactedMessage method returns a human-readable text of this Err which is
+// safe to show outside of an application.
+// If a message template is registered for the reason type, this method
+// renders it with the map returned by RedactedSituation method.
+// If the reason struct has Message method, this method returns its result
+// only when the reason has no field tagged with `redact:"true"`, otherwise
+// returns the reason name because the result may contain redacted values.
+// If neither is available or the rendering fails, this method returns an
+// empty string, not the result of Error method.
+func (err Err) RedactedMessage() string {
+	if err.reason == nil {
+		return ""
+	}
+
+	if m, ok := err.reason.(messager); ok {
+		if hasRedactedField(err.reason) {
+			return err.ReasonName()
+		}
+		return m.Message()
+	}
+
+	tmpl, ok := reasonMessages[reasonType(err.reason)]
+	if ok {
+		var b strings.Builder
+		if e := tmpl.Execute(&b, err.RedactedSituation()); e == nil {
+			return b.String()
+		}
+	}
+
+	return ""
+}
+
+func hasRedactedField(reason interface{}) bool {
+	t := reasonType(reason)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	n := t.NumField()
+	for i := 0; i < n; i++ {
+		if t.Field(i).Tag.Get("redact") == "true" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package reasonederror_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	FailToReadFile struct {
+		Name string
+	}
+	FailToWriteFile struct {
+		Name string
+	}
+	FailToOpenFile struct {
+		Name string
+	}
+	FailToSignIn struct {
+		User     string
+		Password string `redact:"true"`
+	}
+	FailToVerifyToken struct {
+		User  string
+		Token string `redact:"true"`
+	}
+)
+
+func (r FailToOpenFile) Message() string {
+	return "could not open " + r.Name
+}
+
+func (r FailToVerifyToken) Message() string {
+	return "could not authorize " + r.User + " with " + r.Token
+}
+
+func TestErr_Message_ok(t *testing.T) {
+	assert.Equal(t, re.Ok().Message(), "")
+}
+
+func TestErr_Message_reasonHasMessageMethod(t *testing.T) {
+	err := re.NewErr(FailToOpenFile{Name: "a.txt"})
+	assert.Equal(t, err.Message(), "could not open a.txt")
+}
+
+func TestErr_Message_noTemplate(t *testing.T) {
+	err := re.NewErr(FailToWriteFile{Name: "a.txt"})
+	assert.Equal(t, err.Message(), "{reason=FailToWriteFile, Name=a.txt}")
+}
+
+func TestErr_Message_template(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	e := re.SetReasonMessage(FailToReadFile{}, "could not read {{.Name}}")
+	assert.True(t, e.IsOk())
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Message(), "could not read a.txt")
+
+	err = re.NewErr(&FailToReadFile{Name: "b.txt"})
+	assert.Equal(t, err.Message(), "could not read b.txt")
+}
+
+func TestErr_Message_templateReferringCause(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.SetReasonMessage(InvalidValue{}, "{{.Value}} is invalid for {{.Name}}")
+
+	cause := re.NewErr(FailToGetValue{Name: "foo"})
+	err := re.NewErr(InvalidValue{Value: "abc"}, cause)
+	assert.Equal(t, err.Message(), "abc is invalid for foo")
+}
+
+func TestErr_Message_failToExecuteTemplate(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.SetReasonMessage(FailToReadFile{}, "{{.Name.Foo}}")
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"}, errors.New("def"))
+	assert.Equal(t, err.Message(), "{reason=FailToReadFile, Name=a.txt, cause=def}")
+}
+
+func TestSetReasonMessage_badTemplate(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	e := re.SetReasonMessage(FailToReadFile{}, "{{.Name")
+	assert.True(t, e.IsNotOk())
+	switch r := e.Reason().(type) {
+	case re.FailToParseMessageTemplate:
+		assert.Equal(t, r.Reason, "FailToReadFile")
+		assert.Equal(t, r.Template, "{{.Name")
+	default:
+		assert.Fail(t, e.Error())
+	}
+	assert.NotNil(t, e.Cause())
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Message(), "{reason=FailToReadFile, Name=a.txt}")
+}
+
+func TestSetReasonMessage_afterFixErrCfgs(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.FixErrCfgs()
+
+	e := re.SetReasonMessage(FailToReadFile{}, "could not read {{.Name}}")
+	assert.True(t, e.IsOk())
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"})
+	assert.Equal(t, err.Message(), "{reason=FailToReadFile, Name=a.txt}")
+}
+
+func TestSetReasonMessage_nilReason(t *testing.T) {
+	e := re.SetReasonMessage(nil, "abc")
+	assert.True(t, e.IsOk())
+}
+
+func TestErr_RedactedMessage_ok(t *testing.T) {
+	assert.Equal(t, re.Ok().RedactedMessage(), "")
+}
+
+func TestErr_RedactedMessage_reasonHasMessageMethod(t *testing.T) {
+	err := re.NewErr(FailToOpenFile{Name: "a.txt"})
+	assert.Equal(t, err.RedactedMessage(), "could not open a.txt")
+
+	err = re.NewErr(FailToVerifyToken{User: "foo", Token: "secret"})
+	assert.Equal(t, err.RedactedMessage(), "FailToVerifyToken")
+}
+
+func TestErr_RedactedMessage_noTemplate(t *testing.T) {
+	err := re.NewErr(FailToWriteFile{Name: "a.txt"}, errors.New("secret"))
+	assert.Equal(t, err.RedactedMessage(), "")
+}
+
+func TestErr_RedactedMessage_template(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.SetReasonMessage(FailToSignIn{}, "{{.User}} could not log in with {{.Password}}")
+
+	err := re.NewErr(FailToSignIn{User: "foo", Password: "secret"})
+	assert.Equal(t, err.Message(), "foo could not log in with secret")
+	assert.Equal(t, err.RedactedMessage(), "foo could not log in with [REDACTED]")
+}
+
+func TestErr_RedactedMessage_failToExecuteTemplate(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	re.SetReasonMessage(FailToReadFile{}, "{{.Name.Foo}}")
+
+	err := re.NewErr(FailToReadFile{Name: "a.txt"}, errors.New("def"))
+	assert.Equal(t, err.RedactedMessage(), "")
+}
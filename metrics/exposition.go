@@ -0,0 +1,115 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ServeHTTP method serves the metrics in Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	m.Write(w)
+}
+
+// Write method writes the metrics to the specified writer in Prometheus text
+// exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	m.mutex.Lock()
+	m.writeErrs(bw)
+	m.writeDelay(bw)
+	m.writeDropped(bw)
+	m.writeOverflows(bw)
+	m.mutex.Unlock()
+
+	return bw.Flush()
+}
+
+func (m *Metrics) writeErrs(w *bufio.Writer) {
+	name := m.opts.Namespace + "_errs_total"
+	writeHeader(w, name, "counter", "The number of notified Err(s).")
+
+	for _, s := range sortedSeries(m.counts) {
+		w.WriteString(name)
+		writeLabels(w, m.labelNames, s.labels)
+		w.WriteString(" " + strconv.FormatUint(s.count, 10) + "\n")
+	}
+}
+
+func (m *Metrics) writeDelay(w *bufio.Writer) {
+	name := m.opts.Namespace + "_notification_delay_seconds"
+	writeHeader(w, name, "histogram",
+		"The delay from the creation of an Err until the notification.")
+
+	le := []string{"le"}
+	for i, b := range m.opts.Buckets {
+		w.WriteString(name + "_bucket")
+		writeLabels(w, le, []string{formatFloat(b)})
+		w.WriteString(" " + strconv.FormatUint(m.bucketCounts[i], 10) + "\n")
+	}
+	w.WriteString(name + "_bucket")
+	writeLabels(w, le, []string{"+Inf"})
+	w.WriteString(" " + strconv.FormatUint(m.delayCount, 10) + "\n")
+
+	w.WriteString(name + "_sum " + formatFloat(m.delaySum) + "\n")
+	w.WriteString(name + "_count " + strconv.FormatUint(m.delayCount, 10) + "\n")
+}
+
+func (m *Metrics) writeDropped(w *bufio.Writer) {
+	name := m.opts.Namespace + "_dropped_notifications_total"
+	writeHeader(w, name, "counter", "The number of notifications dropped by handlers.")
+
+	handlers := make([]string, 0, len(m.dropped))
+	for h := range m.dropped {
+		handlers = append(handlers, h)
+	}
+	sort.Strings(handlers)
+
+	names := []string{"handler"}
+	for _, h := range handlers {
+		w.WriteString(name)
+		writeLabels(w, names, []string{h})
+		w.WriteString(" " + strconv.FormatUint(m.dropped[h], 10) + "\n")
+	}
+}
+
+func (m *Metrics) writeOverflows(w *bufio.Writer) {
+	name := m.opts.Namespace + "_label_overflows_total"
+	writeHeader(w, name, "counter",
+		"The number of notifications of which label values are replaced by the cardinality guards.")
+	w.WriteString(name + " " + strconv.FormatUint(m.overflows, 10) + "\n")
+}
+
+func writeHeader(w *bufio.Writer, name, typ, help string) {
+	w.WriteString("# HELP " + name + " " + help + "\n")
+	w.WriteString("# TYPE " + name + " " + typ + "\n")
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeLabels(w *bufio.Writer, names, values []string) {
+	w.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteString(name + `="` + labelValueReplacer.Replace(values[i]) + `"`)
+	}
+	w.WriteByte('}')
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
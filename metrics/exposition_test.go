@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/metrics"
+)
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	err := reasonederror.NewErr(FailToConnect{Host: "a"})
+	occ := lastOcc
+
+	m, _ := metrics.NewMetrics(metrics.Options{
+		Buckets: []float64{0.5},
+		Now:     func() time.Time { return occ.Time().Add(250 * time.Millisecond) },
+	})
+	m.Handle(err, occ)
+	m.Dropped("webhook")
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+	assert.Equal(t, rec.Body.String(), `# HELP reasonederror_errs_total The number of notified Err(s).
+# TYPE reasonederror_errs_total counter
+reasonederror_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect"} 1
+# HELP reasonederror_notification_delay_seconds The delay from the creation of an Err until the notification.
+# TYPE reasonederror_notification_delay_seconds histogram
+reasonederror_notification_delay_seconds_bucket{le="0.5"} 1
+reasonederror_notification_delay_seconds_bucket{le="+Inf"} 1
+reasonederror_notification_delay_seconds_sum 0.25
+reasonederror_notification_delay_seconds_count 1
+# HELP reasonederror_dropped_notifications_total The number of notifications dropped by handlers.
+# TYPE reasonederror_dropped_notifications_total counter
+reasonederror_dropped_notifications_total{handler="webhook"} 1
+# HELP reasonederror_label_overflows_total The number of notifications of which label values are replaced by the cardinality guards.
+# TYPE reasonederror_label_overflows_total counter
+reasonederror_label_overflows_total 0
+`)
+}
+
+func TestMetrics_ServeHTTP_empty(t *testing.T) {
+	m, _ := metrics.NewMetrics(metrics.Options{})
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Contains(t, rec.Body.String(), "# TYPE reasonederror_errs_total counter\n# HELP")
+	assert.Contains(t, rec.Body.String(), `reasonederror_notification_delay_seconds_bucket{le="0.005"} 0`)
+}
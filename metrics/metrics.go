@@ -0,0 +1,325 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package metrics provides an Err notification handler which counts
+// notifications, and serves the counts in Prometheus text exposition format
+// without depending on the Prometheus client library.
+//
+//	m, err := metrics.NewMetrics(metrics.Options{SeverityLabel: true})
+//	if err.IsNotOk() {
+//	    ...
+//	}
+//	reasonederror.AddAsyncErrHandler(m.Handle)
+//	reasonederror.FixErrCfgs()
+//
+//	mux.Handle("/metrics", m)
+//
+// The following metrics are exposed, of which names are prefixed with the
+// namespace:
+//
+//   - errs_total: the counter of notifications by package and reason, and by
+//     severity and situation fields if configured,
+//   - notification_delay_seconds: the histogram of the delays from creations
+//     of Err(s) until notifications to Handle method,
+//   - dropped_notifications_total: the counter of notifications dropped by
+//     handlers, reported with Dropped method,
+//   - label_overflows_total: the counter of notifications of which label
+//     values are replaced by the cardinality guards.
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// ReservedLabel is an error reason which indicates that a name in
+	// SituationLabels option is same as a label name which a Metrics uses
+	// by itself: "package", "reason" or "severity".
+	ReservedLabel struct {
+		Name string
+	}
+)
+
+// OtherValue is a label value which replaces values over the cardinality
+// limits.
+const OtherValue = "_other_"
+
+const (
+	defaultNamespace         = "reasonederror"
+	defaultMaxSeries         = 1000
+	defaultMaxValuesPerLabel = 100
+	defaultMaxLabelLength    = 128
+)
+
+// DefaultBuckets are the default upper bounds in seconds of the buckets of the
+// delay histogram.
+var DefaultBuckets = []float64{
+	0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5,
+}
+
+// Options is a struct which configures a Metrics.
+type Options struct {
+	// Namespace is the prefix of metric names.
+	// If this is empty, "reasonederror" is used.
+	Namespace string
+
+	// SeverityLabel specifies whether the counter of notifications has the
+	// severity label.
+	SeverityLabel bool
+
+	// SituationLabels are the names of situation fields which are added to
+	// the counter of notifications as labels.
+	// The values are taken from the redacted situation and formatted with
+	// fmt.Sprint, and an absent value is empty.
+	// "package", "reason" and "severity" cannot be used.
+	SituationLabels []string
+
+	// MaxSeries is the maximum number of series of the counter of
+	// notifications. Notifications over this are counted in a series of which
+	// all label values are OtherValue.
+	// If this is zero or less, 1000 is used.
+	MaxSeries int
+
+	// MaxValuesPerLabel is the maximum number of distinct values of each
+	// situation label. Values over this are replaced with OtherValue.
+	// If this is zero or less, 100 is used.
+	MaxValuesPerLabel int
+
+	// MaxLabelLength is the maximum length in bytes of a label value, and
+	// a longer value is truncated.
+	// If this is zero or less, 128 is used.
+	MaxLabelLength int
+
+	// Buckets are the upper bounds in seconds of the buckets of the delay
+	// histogram, in increasing order.
+	// If this is empty, DefaultBuckets is used.
+	Buckets []float64
+
+	// Now is the function which returns the current time, used to measure
+	// delays.
+	// If this is nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Metrics is a struct which counts Err notifications.
+// The methods of Metrics are safe for concurrent use.
+type Metrics struct {
+	opts       Options
+	labelNames []string
+
+	mutex       sync.Mutex
+	counts      map[string]*series
+	labelValues []map[string]bool
+	overflows   uint64
+	dropped     map[string]uint64
+
+	bucketCounts []uint64
+	delayCount   uint64
+	delaySum     float64
+}
+
+type series struct {
+	labels []string
+	count  uint64
+}
+
+var reservedLabels = map[string]bool{
+	"package":  true,
+	"reason":   true,
+	"severity": true,
+}
+
+// NewMetrics is a function which creates a Metrics with the specified Options.
+// If SituationLabels option contains a reserved label name, this function
+// returns an Err of which reason is ReservedLabel.
+func NewMetrics(opts Options) (*Metrics, reasonederror.Err) {
+	if opts.Namespace == "" {
+		opts.Namespace = defaultNamespace
+	}
+	if opts.MaxSeries <= 0 {
+		opts.MaxSeries = defaultMaxSeries
+	}
+	if opts.MaxValuesPerLabel <= 0 {
+		opts.MaxValuesPerLabel = defaultMaxValuesPerLabel
+	}
+	if opts.MaxLabelLength <= 0 {
+		opts.MaxLabelLength = defaultMaxLabelLength
+	}
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = DefaultBuckets
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
+	labelNames := []string{"package", "reason"}
+	if opts.SeverityLabel {
+		labelNames = append(labelNames, "severity")
+	}
+	for _, name := range opts.SituationLabels {
+		ln := labelName(name)
+		if reservedLabels[ln] {
+			return nil, reasonederror.NewErr(ReservedLabel{Name: name})
+		}
+		labelNames = append(labelNames, ln)
+	}
+
+	m := &Metrics{
+		opts:         opts,
+		labelNames:   labelNames,
+		counts:       make(map[string]*series),
+		labelValues:  make([]map[string]bool, len(opts.SituationLabels)),
+		dropped:      make(map[string]uint64),
+		bucketCounts: make([]uint64, len(opts.Buckets)),
+	}
+	return m, reasonederror.Ok()
+}
+
+// Handle method counts the specified Err, and observes the delay from the
+// time of the ErrOccasion.
+// This method is used as an Err notification handler.
+func (m *Metrics) Handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	var delay float64
+	hasDelay := !occ.Time().IsZero()
+	if hasDelay {
+		delay = m.opts.Now().Sub(occ.Time()).Seconds()
+		if delay < 0 {
+			delay = 0
+		}
+	}
+
+	labels := []string{
+		m.truncate(err.ReasonPackage()),
+		m.truncate(err.ReasonName()),
+	}
+	if m.opts.SeverityLabel {
+		labels = append(labels, err.Severity().String())
+	}
+
+	var situation map[string]interface{}
+	if len(m.opts.SituationLabels) > 0 {
+		situation = err.RedactedSituation()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	overflow := false
+	for i, name := range m.opts.SituationLabels {
+		var v string
+		if x := situation[name]; x != nil {
+			v = m.truncate(fmt.Sprint(x))
+		}
+
+		values := m.labelValues[i]
+		if values == nil {
+			values = make(map[string]bool)
+			m.labelValues[i] = values
+		}
+		if !values[v] {
+			if len(values) >= m.opts.MaxValuesPerLabel {
+				v = OtherValue
+				overflow = true
+			} else {
+				values[v] = true
+			}
+		}
+		labels = append(labels, v)
+	}
+
+	key := strings.Join(labels, "\x00")
+	s, ok := m.counts[key]
+	if !ok {
+		if len(m.counts) >= m.opts.MaxSeries {
+			for i := range labels {
+				labels[i] = OtherValue
+			}
+			key = strings.Join(labels, "\x00")
+			overflow = true
+			s, ok = m.counts[key]
+		}
+		if !ok {
+			s = &series{labels: labels}
+			m.counts[key] = s
+		}
+	}
+	s.count++
+
+	if overflow {
+		m.overflows++
+	}
+
+	if hasDelay {
+		for i, b := range m.opts.Buckets {
+			if delay <= b {
+				m.bucketCounts[i]++
+			}
+		}
+		m.delayCount++
+		m.delaySum += delay
+	}
+}
+
+// Dropped method counts a notification which is dropped by the handler of the
+// specified name, like a handler with a bounded buffer.
+func (m *Metrics) Dropped(handler string) {
+	handler = m.truncate(handler)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.dropped[handler]; !ok && len(m.dropped) >= m.opts.MaxSeries {
+		handler = OtherValue
+	}
+	m.dropped[handler]++
+}
+
+func (m *Metrics) truncate(s string) string {
+	if len(s) <= m.opts.MaxLabelLength {
+		return s
+	}
+	s = s[:m.opts.MaxLabelLength]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// labelName converts a situation field name into a valid label name.
+func labelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '_':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func sortedSeries(counts map[string]*series) []*series {
+	a := make([]*series, 0, len(counts))
+	for _, s := range counts {
+		a = append(a, s)
+	}
+	sort.Slice(a, func(i, j int) bool {
+		x, y := a[i].labels, a[j].labels
+		for k := range x {
+			if x[k] != y[k] {
+				return x[k] < y[k]
+			}
+		}
+		return false
+	})
+	return a
+}
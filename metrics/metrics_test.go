@@ -0,0 +1,194 @@
+package metrics_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/metrics"
+)
+
+type /* error reasons */ (
+	FailToConnect struct {
+		Host  string
+		Port  int
+		Token string `redact:"true"`
+	}
+	RequestTimeout struct{}
+)
+
+func (r RequestTimeout) Severity() reasonederror.Severity {
+	return reasonederror.SeverityWarn
+}
+
+var lastOcc reasonederror.ErrOccasion
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastOcc = occ
+	})
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+func output(t *testing.T, m *metrics.Metrics) string {
+	var b bytes.Buffer
+	assert.Nil(t, m.Write(&b))
+	return b.String()
+}
+
+func lines(t *testing.T, m *metrics.Metrics, prefix string) []string {
+	var a []string
+	for _, line := range strings.Split(output(t, m), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			a = append(a, line)
+		}
+	}
+	return a
+}
+
+func TestMetrics_Handle(t *testing.T) {
+	m, _ := metrics.NewMetrics(metrics.Options{})
+
+	m.Handle(reasonederror.NewErr(FailToConnect{Host: "a"}), lastOcc)
+	m.Handle(reasonederror.NewErr(FailToConnect{Host: "b"}), lastOcc)
+	m.Handle(reasonederror.NewErr(RequestTimeout{}), lastOcc)
+
+	assert.Equal(t, lines(t, m, "reasonederror_errs_total{"), []string{
+		`reasonederror_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect"} 2`,
+		`reasonederror_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="RequestTimeout"} 1`,
+	})
+}
+
+func TestMetrics_Handle_severityAndSituationLabels(t *testing.T) {
+	m, _ := metrics.NewMetrics(metrics.Options{
+		Namespace:       "app",
+		SeverityLabel:   true,
+		SituationLabels: []string{"Host", "Port", "Some-Field"},
+	})
+
+	m.Handle(reasonederror.NewErr(FailToConnect{Host: "a", Port: 80}), lastOcc)
+	m.Handle(reasonederror.NewErr(FailToConnect{Host: "a\"\n\\", Port: 80}), lastOcc)
+	m.Handle(reasonederror.NewErr(RequestTimeout{}), lastOcc)
+
+	assert.Equal(t, lines(t, m, "app_errs_total{"), []string{
+		`app_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect",severity="error",Host="a",Port="80",Some_Field=""} 1`,
+		`app_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect",severity="error",Host="a\"\n\\",Port="80",Some_Field=""} 1`,
+		`app_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="RequestTimeout",severity="warn",Host="",Port="",Some_Field=""} 1`,
+	})
+}
+
+func TestMetrics_Handle_redactedSituation(t *testing.T) {
+	m, _ := metrics.NewMetrics(metrics.Options{
+		SituationLabels: []string{"Host", "Token"},
+	})
+
+	m.Handle(reasonederror.NewErr(FailToConnect{Host: "a", Token: "secret"}), lastOcc)
+
+	assert.Equal(t, lines(t, m, "reasonederror_errs_total{"), []string{
+		`reasonederror_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect",Host="a",Token="[REDACTED]"} 1`,
+	})
+}
+
+func TestNewMetrics_reservedLabel(t *testing.T) {
+	for _, name := range []string{"package", "reason", "severity"} {
+		m, err := metrics.NewMetrics(metrics.Options{
+			SituationLabels: []string{"Host", name},
+		})
+		assert.Nil(t, m)
+		switch r := err.Reason().(type) {
+		case metrics.ReservedLabel:
+			assert.Equal(t, r.Name, name)
+		default:
+			assert.Fail(t, err.Error())
+		}
+	}
+
+	m, err := metrics.NewMetrics(metrics.Options{
+		SituationLabels: []string{"Package", "Reason", "Severity"},
+	})
+	assert.NotNil(t, m)
+	assert.True(t, err.IsOk())
+}
+
+func TestMetrics_Handle_cardinalityGuards(t *testing.T) {
+	m, _ := metrics.NewMetrics(metrics.Options{
+		SituationLabels:   []string{"Host"},
+		MaxValuesPerLabel: 2,
+		MaxLabelLength:    4,
+	})
+
+	for _, host := range []string{"a", "b", "c", "a", "d", "longhost"} {
+		m.Handle(reasonederror.NewErr(FailToConnect{Host: host}), lastOcc)
+	}
+
+	assert.Equal(t, lines(t, m, "reasonederror_errs_total{"), []string{
+		`reasonederror_errs_total{package="gith",reason="Fail",Host="_other_"} 3`,
+		`reasonederror_errs_total{package="gith",reason="Fail",Host="a"} 2`,
+		`reasonederror_errs_total{package="gith",reason="Fail",Host="b"} 1`,
+	})
+	assert.Equal(t, lines(t, m, "reasonederror_label_overflows_total "), []string{
+		"reasonederror_label_overflows_total 3",
+	})
+
+	m, _ = metrics.NewMetrics(metrics.Options{
+		SituationLabels: []string{"Host"},
+		MaxSeries:       2,
+	})
+	for _, host := range []string{"a", "b", "c", "a", "d"} {
+		m.Handle(reasonederror.NewErr(FailToConnect{Host: host}), lastOcc)
+	}
+	assert.Equal(t, lines(t, m, "reasonederror_errs_total{"), []string{
+		`reasonederror_errs_total{package="_other_",reason="_other_",Host="_other_"} 2`,
+		`reasonederror_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect",Host="a"} 2`,
+		`reasonederror_errs_total{package="github.com/sttk/reasonederror/metrics_test",reason="FailToConnect",Host="b"} 1`,
+	})
+}
+
+func TestMetrics_Handle_delay(t *testing.T) {
+	err := reasonederror.NewErr(RequestTimeout{})
+	occ := lastOcc
+
+	delay := 3 * time.Millisecond
+	m, _ := metrics.NewMetrics(metrics.Options{
+		Buckets: []float64{0.001, 0.01},
+		Now:     func() time.Time { return occ.Time().Add(delay) },
+	})
+
+	m.Handle(err, occ)
+	delay = 500 * time.Microsecond
+	m.Handle(err, occ)
+	delay = time.Second
+	m.Handle(err, occ)
+	delay = -time.Second
+	m.Handle(err, occ)
+	m.Handle(err, reasonederror.ErrOccasion{})
+
+	assert.Equal(t, lines(t, m, "reasonederror_notification_delay_seconds"), []string{
+		`reasonederror_notification_delay_seconds_bucket{le="0.001"} 2`,
+		`reasonederror_notification_delay_seconds_bucket{le="0.01"} 3`,
+		`reasonederror_notification_delay_seconds_bucket{le="+Inf"} 4`,
+		`reasonederror_notification_delay_seconds_sum 1.0035`,
+		`reasonederror_notification_delay_seconds_count 4`,
+	})
+}
+
+func TestMetrics_Dropped(t *testing.T) {
+	m, _ := metrics.NewMetrics(metrics.Options{MaxSeries: 2})
+
+	m.Dropped("webhook")
+	m.Dropped("webhook")
+	m.Dropped("syslog")
+	m.Dropped("sentry")
+
+	assert.Equal(t, lines(t, m, "reasonederror_dropped_notifications_total{"), []string{
+		`reasonederror_dropped_notifications_total{handler="_other_"} 1`,
+		`reasonederror_dropped_notifications_total{handler="syslog"} 1`,
+		`reasonederror_dropped_notifications_total{handler="webhook"} 2`,
+	})
+}
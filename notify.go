@@ -14,9 +14,17 @@ import (
 // ErrOccasion is a struct which contains time and posision in a source file
 // when an Err occured.
 type ErrOccasion struct {
-	time time.Time
-	file string
-	line int
+	time  time.Time
+	file  string
+	line  int
+	attrs *occasionAttrs
+}
+
+// occasionAttrs is a struct which has attributes of an ErrOccasion.
+// Because the map is shared by copies of an ErrOccasion, it is never modified
+// after creation.
+type occasionAttrs struct {
+	m map[string]interface{}
 }
 
 // Time is a method which returns time when this Err occured.
@@ -34,6 +42,37 @@ func (e ErrOccasion) Line() int {
 	return e.line
 }
 
+// Attrs is a method which returns the attributes attached to this
+// ErrOccasion with SetErrOccasionAttrs function or by enrichers added with
+// AddErrOccasionEnricher function.
+// The returned map is a copy, and is nil if there is no attribute.
+func (e ErrOccasion) Attrs() map[string]interface{} {
+	if e.attrs == nil {
+		return nil
+	}
+	m := make(map[string]interface{}, len(e.attrs.m))
+	for k, v := range e.attrs.m {
+		m[k] = v
+	}
+	return m
+}
+
+// SetAttr is a method which sets an attribute to this ErrOccasion.
+// This method is used in enrichers added with AddErrOccasionEnricher function.
+func (e *ErrOccasion) SetAttr(name string, value interface{}) {
+	var m map[string]interface{}
+	if e.attrs == nil {
+		m = make(map[string]interface{}, 1)
+	} else {
+		m = make(map[string]interface{}, len(e.attrs.m)+1)
+		for k, v := range e.attrs.m {
+			m[k] = v
+		}
+	}
+	m[name] = value
+	e.attrs = &occasionAttrs{m}
+}
+
 type handlerListElem struct {
 	handler func(Err, ErrOccasion)
 	next    *handlerListElem
@@ -45,10 +84,12 @@ type handlerList struct {
 }
 
 var (
-	syncErrHandlers  = handlerList{nil, nil}
-	asyncErrHandlers = handlerList{nil, nil}
-	isErrCfgsFixed   = false
-	errCfgMutex      = sync.Mutex{}
+	syncErrHandlers      = handlerList{nil, nil}
+	asyncErrHandlers     = handlerList{nil, nil}
+	errOccasionEnrichers []func(*ErrOccasion)
+	errOccasionAttrs     *occasionAttrs
+	isErrCfgsFixed       = false
+	errCfgMutex          = sync.Mutex{}
 )
 
 // Adds an Err creation event handler which is executed synchronously.
@@ -61,20 +102,44 @@ func AddSyncErrHandler(handler func(Err, ErrOccasion)) {
 		return
 	}
 
-	last := syncErrHandlers.last
-	syncErrHandlers.last = &handlerListElem{handler, nil}
+	addErrHandler(&syncErrHandlers, handler)
+}
+
+// Adds a Err creation event handlers which is executed asynchronously.
+func AddAsyncErrHandler(handler func(Err, ErrOccasion)) {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return
+	}
+
+	addErrHandler(&asyncErrHandlers, handler)
+}
+
+func addErrHandler(list *handlerList, handler func(Err, ErrOccasion)) {
+	last := list.last
+	list.last = &handlerListElem{handler, nil}
 
 	if last != nil {
-		last.next = syncErrHandlers.last
+		last.next = list.last
 	}
 
-	if syncErrHandlers.head == nil {
-		syncErrHandlers.head = syncErrHandlers.last
+	if list.head == nil {
+		list.head = list.last
 	}
 }
 
-// Adds a Err creation event handlers which is executed asynchronously.
-func AddAsyncErrHandler(handler func(Err, ErrOccasion)) {
+// AddErrOccasionEnricher is a function which adds an enricher of ErrOccasion.
+// Enrichers are executed in the order of addition once for each Err creation
+// event before any handlers, and can attach attributes to the ErrOccasion
+// with SetAttr method.
+// This function is effective only before calling FixErrCfgs function.
+//
+//	reasonederror.AddErrOccasionEnricher(func(occ *reasonederror.ErrOccasion) {
+//	    occ.SetAttr("goroutines", runtime.NumGoroutine())
+//	})
+func AddErrOccasionEnricher(enricher func(*ErrOccasion)) {
 	errCfgMutex.Lock()
 	defer errCfgMutex.Unlock()
 
@@ -82,43 +147,84 @@ func AddAsyncErrHandler(handler func(Err, ErrOccasion)) {
 		return
 	}
 
-	last := asyncErrHandlers.last
-	asyncErrHandlers.last = &handlerListElem{handler, nil}
+	errOccasionEnrichers = append(errOccasionEnrichers, enricher)
+}
+
+// SetErrOccasionAttrs is a function which sets attributes attached to every
+// ErrOccasion, like a service name and a version.
+// The attributes of the same names set previously are overwritten.
+// This function is effective only before calling FixErrCfgs function.
+func SetErrOccasionAttrs(attrs map[string]interface{}) {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
 
-	if last != nil {
-		last.next = asyncErrHandlers.last
+	if isErrCfgsFixed {
+		return
 	}
 
-	if asyncErrHandlers.head == nil {
-		asyncErrHandlers.head = asyncErrHandlers.last
+	var occ ErrOccasion
+	occ.attrs = errOccasionAttrs
+	for k, v := range attrs {
+		occ.SetAttr(k, v)
 	}
+	errOccasionAttrs = occ.attrs
 }
 
 // Fixes configuration for Err creation event handlers.
 // After calling this function, handlers cannot be registered interface{} more and the
 // notification becomes effective.
 func FixErrCfgs() {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
 	isErrCfgsFixed = true
 }
 
+// IsErrCfgsFixed is a function which checks whether FixErrCfgs function has
+// been called.
+// This function is useful for packages which register handlers, to report
+// that the registration is not effective.
+func IsErrCfgsFixed() bool {
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	return isErrCfgsFixed
+}
+
 func notifyErr(err Err) {
-	if !isErrCfgsFixed {
+	if !isErrNotifiable() {
 		return
 	}
 
-	if syncErrHandlers.head == nil && asyncErrHandlers.head == nil {
-		return
+	notifyErrWithOccasion(err, newErrOccasion(runtime.Caller(2)))
+}
+
+func isErrNotifiable() bool {
+	if !isErrCfgsFixed {
+		return false
 	}
 
+	return syncErrHandlers.head != nil || asyncErrHandlers.head != nil
+}
+
+func newErrOccasion(_ uintptr, file string, line int, ok bool) ErrOccasion {
 	var occ ErrOccasion
 	occ.time = time.Now()
 
-	_, file, line, ok := runtime.Caller(2)
 	if ok {
 		occ.file = filepath.Base(file)
 		occ.line = line
 	}
 
+	return occ
+}
+
+func notifyErrWithOccasion(err Err, occ ErrOccasion) {
+	occ.attrs = errOccasionAttrs
+	for _, enricher := range errOccasionEnrichers {
+		enricher(&occ)
+	}
+
 	for el := syncErrHandlers.head; el != nil; el = el.next {
 		el.handler(err, occ)
 	}
@@ -5,9 +5,11 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
 )
 
 type ReasonForNotification struct{}
@@ -17,7 +19,14 @@ func ClearErrHandlers() {
 	syncErrHandlers.last = nil
 	asyncErrHandlers.head = nil
 	asyncErrHandlers.last = nil
+	errHandlerClosers = nil
+	errOccasionEnrichers = nil
+	errOccasionAttrs = nil
 	isErrCfgsFixed = false
+	reasonMessages = make(map[reflect.Type]*template.Template)
+	catalogMessages = make(map[string]map[string]*template.Template)
+	localeFallbacks = make(map[string][]language.Tag)
+	reasonAttrs = make(map[reflect.Type]ReasonAttrs)
 }
 
 func TestAddErrSyncHandler_oneHandler(t *testing.T) {
@@ -202,13 +211,102 @@ func TestNotifyErr_withHandlers(t *testing.T) {
 
 	assert.Equal(t, syncLogs.Len(), 2)
 	assert.Equal(t, syncLogs.Front().Value,
-		"ReasonForNotification-1:notify_test.go:199")
+		"ReasonForNotification-1:notify_test.go:208")
 	assert.Equal(t, syncLogs.Front().Next().Value,
-		"ReasonForNotification-2:notify_test.go:199")
+		"ReasonForNotification-2:notify_test.go:208")
 
 	time.Sleep(100 * time.Millisecond)
 
 	assert.Equal(t, asyncLogs.Len(), 1)
 	assert.Equal(t, asyncLogs.Front().Value,
-		"ReasonForNotification-3:notify_test.go:199")
+		"ReasonForNotification-3:notify_test.go:208")
+}
+
+func TestSetErrOccasionAttrs(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	var attrs []map[string]interface{}
+	AddSyncErrHandler(func(err Err, occ ErrOccasion) {
+		attrs = append(attrs, occ.Attrs())
+	})
+
+	NewErr(ReasonForNotification{})
+
+	SetErrOccasionAttrs(map[string]interface{}{"service": "api", "version": "1.0"})
+	SetErrOccasionAttrs(map[string]interface{}{"version": "1.1"})
+	FixErrCfgs()
+	SetErrOccasionAttrs(map[string]interface{}{"host": "h1"})
+
+	NewErr(ReasonForNotification{})
+	NewErr(ReasonForNotification{})
+
+	assert.Equal(t, len(attrs), 2)
+	assert.Equal(t, attrs[0], map[string]interface{}{"service": "api", "version": "1.1"})
+
+	attrs[0]["service"] = "changed"
+	assert.Equal(t, attrs[1], map[string]interface{}{"service": "api", "version": "1.1"})
+}
+
+func TestAddErrOccasionEnricher(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	var calls []string
+	var syncOcc ErrOccasion
+	asyncOcc := make(chan ErrOccasion, 1)
+
+	SetErrOccasionAttrs(map[string]interface{}{"service": "api"})
+	AddErrOccasionEnricher(func(occ *ErrOccasion) {
+		calls = append(calls, "enricher1")
+		occ.SetAttr("file", occ.File())
+	})
+	AddErrOccasionEnricher(func(occ *ErrOccasion) {
+		calls = append(calls, "enricher2")
+		occ.SetAttr("service", "api2")
+	})
+	AddSyncErrHandler(func(err Err, occ ErrOccasion) {
+		calls = append(calls, "handler")
+		syncOcc = occ
+		occ.SetAttr("service", "modified")
+	})
+	AddAsyncErrHandler(func(err Err, occ ErrOccasion) {
+		asyncOcc <- occ
+	})
+	FixErrCfgs()
+	AddErrOccasionEnricher(func(occ *ErrOccasion) {
+		calls = append(calls, "enricher3")
+	})
+
+	NewErr(ReasonForNotification{})
+
+	assert.Equal(t, calls, []string{"enricher1", "enricher2", "handler"})
+	assert.Equal(t, syncOcc.Attrs(), map[string]interface{}{
+		"service": "api2", "file": "notify_test.go",
+	})
+	assert.Equal(t, (<-asyncOcc).Attrs(), map[string]interface{}{
+		"service": "api2", "file": "notify_test.go",
+	})
+	assert.Equal(t, errOccasionAttrs.m, map[string]interface{}{"service": "api"})
+}
+
+func TestErrOccasion_Attrs_none(t *testing.T) {
+	var occ ErrOccasion
+	assert.Nil(t, occ.Attrs())
+
+	occ.SetAttr("a", 1)
+	copied := occ
+	copied.SetAttr("b", 2)
+
+	assert.Equal(t, occ.Attrs(), map[string]interface{}{"a": 1})
+	assert.Equal(t, copied.Attrs(), map[string]interface{}{"a": 1, "b": 2})
+}
+
+func TestIsErrCfgsFixed(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	assert.False(t, IsErrCfgsFixed())
+	FixErrCfgs()
+	assert.True(t, IsErrCfgsFixed())
 }
@@ -0,0 +1,23 @@
+module github.com/sttk/reasonederror/otelerr
+
+go 1.18
+
+replace github.com/sttk/reasonederror => ../
+
+require (
+	github.com/stretchr/testify v1.8.2
+	github.com/sttk/reasonederror v0.5.0
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/sdk v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.7.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
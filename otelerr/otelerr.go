@@ -0,0 +1,144 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package otelerr provides a function to record reasonederror.Err(s) on
+// OpenTelemetry spans.
+//
+//	func getUser(ctx context.Context, id string) reasonederror.Err {
+//	    ...
+//	    return otelerr.Record(ctx, reasonederror.NewErr(UserNotFound{ID: id}))
+//	}
+//
+// An Err is recorded as an "exception" event of the span in the context, and
+// the status of the span is set to Error.
+package otelerr
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sttk/reasonederror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of an exception event other than the ones defined by the
+// semantic conventions.
+// The fields of the redacted situation are added with the keys of which
+// prefix is SituationKeyPrefix, like "reasonederror.situation.Host".
+const (
+	ReasonKey          = attribute.Key("reasonederror.reason")
+	PackageKey         = attribute.Key("reasonederror.package")
+	SeverityKey        = attribute.Key("reasonederror.severity")
+	SituationKeyPrefix = "reasonederror.situation."
+)
+
+const (
+	exceptionEventName     = "exception"
+	exceptionTypeKey       = attribute.Key("exception.type")
+	exceptionMessageKey    = attribute.Key("exception.message")
+	exceptionStacktraceKey = attribute.Key("exception.stacktrace")
+)
+
+const maxStackDepth = 64
+
+// Record is a function which records the specified Err on the span in the
+// specified context, and returns the Err as it is.
+//
+// The Err is added as an "exception" event which has the following
+// attributes:
+//
+//   - exception.type: the package path and the name of the reason type,
+//   - exception.message: the result of RedactedMessage method if it is not
+//     empty, otherwise the name of the reason type,
+//   - exception.stacktrace: the stack trace of the caller of this function,
+//   - reasonederror.reason, reasonederror.package, reasonederror.severity,
+//   - reasonederror.situation.*: the fields of the redacted situation.
+//
+// And the status of the span is set to Error with the exception message.
+// If the Err is Ok or the span is not recording, this function does nothing.
+func Record(ctx context.Context, err reasonederror.Err) reasonederror.Err {
+	if err.IsOk() {
+		return err
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return err
+	}
+
+	msg := message(err)
+
+	attrs := []attribute.KeyValue{
+		exceptionTypeKey.String(err.ReasonPackage() + "." + err.ReasonName()),
+		exceptionMessageKey.String(msg),
+		exceptionStacktraceKey.String(stacktrace(2)),
+		ReasonKey.String(err.ReasonName()),
+		PackageKey.String(err.ReasonPackage()),
+		SeverityKey.String(err.Severity().String()),
+	}
+	attrs = append(attrs, situationAttrs(err)...)
+
+	span.AddEvent(exceptionEventName, trace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, msg)
+
+	return err
+}
+
+// message returns the redacted message of an Err, or the reason name if the
+// reason has no message.
+func message(err reasonederror.Err) string {
+	if msg := err.RedactedMessage(); msg != "" {
+		return msg
+	}
+	return err.ReasonName()
+}
+
+func situationAttrs(err reasonederror.Err) []attribute.KeyValue {
+	m := err.RedactedSituation()
+
+	attrs := make([]attribute.KeyValue, 0, len(m))
+	for k, v := range m {
+		key := attribute.Key(SituationKeyPrefix + k)
+		switch x := v.(type) {
+		case string:
+			attrs = append(attrs, key.String(x))
+		case bool:
+			attrs = append(attrs, key.Bool(x))
+		case int:
+			attrs = append(attrs, key.Int(x))
+		case int64:
+			attrs = append(attrs, key.Int64(x))
+		case float64:
+			attrs = append(attrs, key.Float64(x))
+		case []string:
+			attrs = append(attrs, key.StringSlice(x))
+		case nil:
+			attrs = append(attrs, key.String(""))
+		default:
+			attrs = append(attrs, key.String(fmt.Sprint(x)))
+		}
+	}
+	return attrs
+}
+
+func stacktrace(skip int) string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	for {
+		f, more := frames.Next()
+		b.WriteString(f.Function + "\n\t" + f.File + ":" + strconv.Itoa(f.Line) + "\n")
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
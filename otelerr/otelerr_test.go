@@ -0,0 +1,154 @@
+package otelerr_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/otelerr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type /* error reasons */ (
+	FailToLogin struct {
+		User     string
+		Password string `redact:"true"`
+		Attempts int
+		Locked   bool
+		Roles    []string
+		Cause    error
+	}
+	UserNotFound struct {
+		ID string
+	}
+	FailToSignUp struct {
+		User     string
+		Password string `redact:"true"`
+	}
+)
+
+func (r UserNotFound) Message() string {
+	return "the user is not found"
+}
+
+func (r UserNotFound) Severity() reasonederror.Severity {
+	return reasonederror.SeverityWarn
+}
+
+func newTracer() (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	return sr, tp
+}
+
+func attrMap(attrs []attribute.KeyValue) map[string]interface{} {
+	m := make(map[string]interface{})
+	for _, a := range attrs {
+		m[string(a.Key)] = a.Value.AsInterface()
+	}
+	return m
+}
+
+func login(ctx context.Context) reasonederror.Err {
+	return otelerr.Record(ctx, reasonederror.NewErr(FailToLogin{
+		User: "alice", Password: "secret", Attempts: 3, Locked: true,
+		Roles: []string{"admin", "dev"},
+	}))
+}
+
+func TestRecord(t *testing.T) {
+	sr, tp := newTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "login")
+
+	err := login(ctx)
+	span.End()
+
+	assert.Equal(t, err.ReasonName(), "FailToLogin")
+
+	spans := sr.Ended()
+	assert.Equal(t, len(spans), 1)
+	assert.Equal(t, spans[0].Status().Code, codes.Error)
+	assert.Equal(t, spans[0].Status().Description, "FailToLogin")
+
+	events := spans[0].Events()
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Name, "exception")
+
+	m := attrMap(events[0].Attributes)
+	stack := m["exception.stacktrace"].(string)
+	delete(m, "exception.stacktrace")
+
+	assert.Equal(t, m, map[string]interface{}{
+		"exception.type":                   "github.com/sttk/reasonederror/otelerr_test.FailToLogin",
+		"exception.message":                "FailToLogin",
+		"reasonederror.reason":             "FailToLogin",
+		"reasonederror.package":            "github.com/sttk/reasonederror/otelerr_test",
+		"reasonederror.severity":           "error",
+		"reasonederror.situation.User":     "alice",
+		"reasonederror.situation.Password": reasonederror.RedactedValue,
+		"reasonederror.situation.Attempts": int64(3),
+		"reasonederror.situation.Locked":   true,
+		"reasonederror.situation.Roles":    []string{"admin", "dev"},
+		"reasonederror.situation.Cause":    "",
+	})
+	assert.True(t, strings.HasPrefix(stack, "github.com/sttk/reasonederror/otelerr_test.login\n"), stack)
+	assert.Contains(t, stack, "otelerr_test.go:")
+}
+
+func TestRecord_withMessage(t *testing.T) {
+	sr, tp := newTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "get")
+
+	cause := reasonederror.NewErr(UserNotFound{ID: "u1"})
+	otelerr.Record(ctx, cause)
+	span.End()
+
+	s := sr.Ended()[0]
+	assert.Equal(t, s.Status().Description, "the user is not found")
+
+	m := attrMap(s.Events()[0].Attributes)
+	assert.Equal(t, m["exception.message"], "the user is not found")
+	assert.Equal(t, m["reasonederror.severity"], "warn")
+	assert.Equal(t, m["reasonederror.situation.ID"], "u1")
+}
+
+func TestRecord_okOrNoSpan(t *testing.T) {
+	sr, tp := newTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "ok")
+
+	assert.True(t, otelerr.Record(ctx, reasonederror.Ok()).IsOk())
+	span.End()
+
+	s := sr.Ended()[0]
+	assert.Equal(t, len(s.Events()), 0)
+	assert.Equal(t, s.Status().Code, codes.Unset)
+
+	err := otelerr.Record(context.Background(), reasonederror.NewErr(UserNotFound{}))
+	assert.Equal(t, err.ReasonName(), "UserNotFound")
+
+	ctx, span = tp.Tracer("test").Start(context.Background(), "ended")
+	span.End()
+	otelerr.Record(ctx, reasonederror.NewErr(UserNotFound{}))
+	assert.Equal(t, len(sr.Ended()[1].Events()), 0)
+}
+
+func TestRecord_withRedactedMessage(t *testing.T) {
+	reasonederror.SetReasonMessage(FailToSignUp{}, "sign-up failed for {{.User}} / {{.Password}}")
+
+	sr, tp := newTracer()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "signup")
+
+	otelerr.Record(ctx, reasonederror.NewErr(FailToSignUp{User: "alice", Password: "secret"}))
+	span.End()
+
+	s := sr.Ended()[0]
+	assert.Equal(t, s.Status().Description, "sign-up failed for alice / [REDACTED]")
+
+	m := attrMap(s.Events()[0].Attributes)
+	assert.Equal(t, m["exception.message"], "sign-up failed for alice / [REDACTED]")
+}
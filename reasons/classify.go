@@ -0,0 +1,251 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasons
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net"
+	"sync"
+	"syscall"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// FileNotFound is an error reason which indicates that a file does not
+	// exist.
+	FileNotFound struct {
+		Op   string
+		Path string
+	}
+
+	// FileExists is an error reason which indicates that a file already
+	// exists.
+	FileExists struct {
+		Op   string
+		Path string
+	}
+
+	// PermissionDenied is an error reason which indicates that an operation on
+	// a file is not permitted.
+	PermissionDenied struct {
+		Op   string
+		Path string
+	}
+
+	// FileOperationFailed is an error reason which indicates that an operation
+	// on a file failed for the other reasons.
+	FileOperationFailed struct {
+		Op   string
+		Path string
+	}
+
+	// ConnectionRefused is an error reason which indicates that a connection
+	// was refused by the remote host.
+	ConnectionRefused struct {
+		Op   string
+		Addr string
+	}
+
+	// ConnectionReset is an error reason which indicates that a connection
+	// was reset by the remote host.
+	ConnectionReset struct {
+		Op   string
+		Addr string
+	}
+
+	// AddressInUse is an error reason which indicates that a local address is
+	// already in use.
+	AddressInUse struct {
+		Op   string
+		Addr string
+	}
+
+	// Timeout is an error reason which indicates that an I/O operation timed
+	// out.
+	Timeout struct {
+		Op   string
+		Addr string
+	}
+
+	// NetworkOperationFailed is an error reason which indicates that a network
+	// operation failed for the other reasons.
+	NetworkOperationFailed struct {
+		Op   string
+		Net  string
+		Addr string
+	}
+
+	// EndOfFile is an error reason which indicates that no more input is
+	// available.
+	EndOfFile struct{}
+
+	// UnexpectedEOF is an error reason which indicates that the end of input
+	// was encountered in the middle of reading a fixed-size block or a data
+	// structure.
+	UnexpectedEOF struct{}
+
+	// SyscallFailed is an error reason which indicates that a system call
+	// failed with an error number.
+	SyscallFailed struct {
+		Errno syscall.Errno
+	}
+
+	// Unclassified is an error reason which indicates that an error cannot be
+	// classified into the other reasons.
+	Unclassified struct{}
+)
+
+// Class method returns ClassSecurity.
+func (r PermissionDenied) Class() reasonederror.Class {
+	return reasonederror.ClassSecurity
+}
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r ConnectionRefused) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r ConnectionReset) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r Timeout) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Classifier is a function type which classifies an error into an Err.
+// If a Classifier cannot classify the error, it returns false as the second
+// result.
+type Classifier func(err error) (reasonederror.Err, bool)
+
+var (
+	classifiers    []Classifier
+	classifierLock = sync.RWMutex{}
+)
+
+// RegisterClassifier is a function which registers a Classifier used by
+// Classify function.
+// Registered Classifiers are tried in the order of registration before the
+// built-in classification.
+func RegisterClassifier(classifier Classifier) {
+	classifierLock.Lock()
+	defer classifierLock.Unlock()
+
+	classifiers = append(classifiers, classifier)
+}
+
+// Classify is a function which converts the specified error into an Err with
+// a well-known reason in this package.
+// The error is set as the cause of the Err.
+//
+// This function tries the Classifiers registered with RegisterClassifier
+// function first, and then classifies the error as follows:
+//
+//   - context.Canceled and context.DeadlineExceeded into Canceled and
+//     DeadlineExceeded,
+//   - io.EOF and io.ErrUnexpectedEOF into EndOfFile and UnexpectedEOF,
+//   - *fs.PathError into FileNotFound, FileExists, PermissionDenied, Timeout,
+//     or FileOperationFailed,
+//   - *net.OpError into ConnectionRefused, ConnectionReset, AddressInUse,
+//     Timeout, or NetworkOperationFailed,
+//   - syscall.Errno into SyscallFailed,
+//   - the others into Unclassified.
+//
+// If the error is already an Err, this function returns it as it is, and if
+// the error is nil, this function returns Ok.
+func Classify(err error) reasonederror.Err {
+	if err == nil {
+		return reasonederror.Ok()
+	}
+
+	if re, ok := err.(reasonederror.Err); ok {
+		return re
+	}
+
+	classifierLock.RLock()
+	cs := classifiers
+	classifierLock.RUnlock()
+
+	for _, classify := range cs {
+		if re, ok := classify(err); ok {
+			return re
+		}
+	}
+
+	return reasonederror.NewErr(classify(err), err)
+}
+
+func classify(err error) interface{} {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Canceled{}
+	case errors.Is(err, context.DeadlineExceeded):
+		return DeadlineExceeded{}
+	case errors.Is(err, io.EOF):
+		return EndOfFile{}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return UnexpectedEOF{}
+	}
+
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return classifyPathError(pathErr)
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return classifyOpError(opErr)
+	}
+
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return SyscallFailed{Errno: errno}
+	}
+
+	return Unclassified{}
+}
+
+func classifyPathError(err *fs.PathError) interface{} {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return FileNotFound{Op: err.Op, Path: err.Path}
+	case errors.Is(err, fs.ErrExist):
+		return FileExists{Op: err.Op, Path: err.Path}
+	case errors.Is(err, fs.ErrPermission):
+		return PermissionDenied{Op: err.Op, Path: err.Path}
+	case err.Timeout():
+		return Timeout{Op: err.Op, Addr: err.Path}
+	default:
+		return FileOperationFailed{Op: err.Op, Path: err.Path}
+	}
+}
+
+func classifyOpError(err *net.OpError) interface{} {
+	addr := ""
+	if err.Addr != nil {
+		addr = err.Addr.String()
+	} else if err.Source != nil {
+		addr = err.Source.String()
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ConnectionRefused{Op: err.Op, Addr: addr}
+	case errors.Is(err, syscall.ECONNRESET):
+		return ConnectionReset{Op: err.Op, Addr: addr}
+	case errors.Is(err, syscall.EADDRINUSE):
+		return AddressInUse{Op: err.Op, Addr: addr}
+	case err.Timeout():
+		return Timeout{Op: err.Op, Addr: addr}
+	default:
+		return NetworkOperationFailed{Op: err.Op, Net: err.Net, Addr: addr}
+	}
+}
@@ -0,0 +1,252 @@
+package reasons_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/reasons"
+)
+
+type /* error reasons */ (
+	QuotaExceeded struct{}
+)
+
+var errQuota = errors.New("quota exceeded")
+
+func TestClassify_nilAndErr(t *testing.T) {
+	assert.True(t, reasons.Classify(nil).IsOk())
+
+	err := reasonederror.NewErr(QuotaExceeded{})
+	assert.Equal(t, reasons.Classify(err), err)
+}
+
+func TestClassify_fileNotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.txt")
+	_, e := os.Open(path)
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.FileNotFound:
+		assert.Equal(t, r.Op, "open")
+		assert.Equal(t, r.Path, path)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause(), e)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestClassify_fileExists(t *testing.T) {
+	dir := t.TempDir()
+	e := os.Mkdir(dir, 0755)
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.FileExists:
+		assert.Equal(t, r.Op, "mkdir")
+		assert.Equal(t, r.Path, dir)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestClassify_permissionDenied(t *testing.T) {
+	e := &fs.PathError{Op: "open", Path: "/etc/shadow", Err: syscall.EACCES}
+
+	err := reasons.Classify(fmt.Errorf("wrapped: %w", e))
+	switch r := err.Reason().(type) {
+	case reasons.PermissionDenied:
+		assert.Equal(t, r.Op, "open")
+		assert.Equal(t, r.Path, "/etc/shadow")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, err.IsSecurityRelevant())
+}
+
+func TestClassify_fileOperationFailed(t *testing.T) {
+	f, e := os.Create(filepath.Join(t.TempDir(), "a.txt"))
+	assert.Nil(t, e)
+	f.Close()
+	_, e = f.Write([]byte("x"))
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.FileOperationFailed:
+		assert.Equal(t, r.Op, "write")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestClassify_connectionRefused(t *testing.T) {
+	lis, e := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	addr := lis.Addr().String()
+	lis.Close()
+
+	_, e = net.Dial("tcp", addr)
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.ConnectionRefused:
+		assert.Equal(t, r.Op, "dial")
+		assert.Equal(t, r.Addr, addr)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, err.IsRetryable())
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+}
+
+func TestClassify_addressInUse(t *testing.T) {
+	lis, e := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	defer lis.Close()
+	addr := lis.Addr().String()
+
+	_, e = net.Listen("tcp", addr)
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.AddressInUse:
+		assert.Equal(t, r.Op, "listen")
+		assert.Equal(t, r.Addr, addr)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestClassify_timeout(t *testing.T) {
+	lis, e := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	defer lis.Close()
+
+	conn, e := net.Dial("tcp", lis.Addr().String())
+	assert.Nil(t, e)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(-time.Second))
+	_, e = conn.Read(make([]byte, 1))
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.Timeout:
+		assert.Equal(t, r.Op, "read")
+		assert.Equal(t, r.Addr, lis.Addr().String())
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, err.IsRetryable())
+}
+
+func TestClassify_networkOperationFailed(t *testing.T) {
+	lis, e := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	lis.Close()
+
+	_, e = lis.Accept()
+
+	err := reasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case reasons.NetworkOperationFailed:
+		assert.Equal(t, r.Op, "accept")
+		assert.Equal(t, r.Net, "tcp")
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestClassify_eof(t *testing.T) {
+	err := reasons.Classify(io.EOF)
+	switch err.Reason().(type) {
+	case reasons.EndOfFile:
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	_, e := io.ReadFull(strings.NewReader("ab"), make([]byte, 3))
+	err = reasons.Classify(e)
+	switch err.Reason().(type) {
+	case reasons.UnexpectedEOF:
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func TestClassify_context(t *testing.T) {
+	err := reasons.Classify(context.Canceled)
+	switch err.Reason().(type) {
+	case reasons.Canceled:
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	err = reasons.Classify(context.DeadlineExceeded)
+	switch err.Reason().(type) {
+	case reasons.DeadlineExceeded:
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestClassify_errno(t *testing.T) {
+	err := reasons.Classify(syscall.ENOSPC)
+	switch r := err.Reason().(type) {
+	case reasons.SyscallFailed:
+		assert.Equal(t, r.Errno, syscall.ENOSPC)
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
+
+func TestClassify_unclassified(t *testing.T) {
+	e := errors.New("something")
+
+	err := reasons.Classify(e)
+	switch err.Reason().(type) {
+	case reasons.Unclassified:
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause(), e)
+}
+
+func TestRegisterClassifier(t *testing.T) {
+	reasons.RegisterClassifier(func(err error) (reasonederror.Err, bool) {
+		return reasonederror.Ok(), false
+	})
+	reasons.RegisterClassifier(func(err error) (reasonederror.Err, bool) {
+		if errors.Is(err, errQuota) {
+			return reasonederror.NewErr(QuotaExceeded{}, err), true
+		}
+		return reasonederror.Ok(), false
+	})
+
+	err := reasons.Classify(fmt.Errorf("write: %w", errQuota))
+	switch err.Reason().(type) {
+	case QuotaExceeded:
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	err = reasons.Classify(io.EOF)
+	switch err.Reason().(type) {
+	case reasons.EndOfFile:
+	default:
+		assert.Fail(t, err.Error())
+	}
+}
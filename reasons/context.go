@@ -0,0 +1,84 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package reasons provides well-known error reasons for errors of the Go
+// standard library, and functions to create reasonederror.Err(s) of them.
+package reasons
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// Canceled is an error reason which indicates that a context was canceled.
+	// The cause is context.Canceled.
+	Canceled struct{}
+
+	// DeadlineExceeded is an error reason which indicates that the deadline of
+	// a context passed.
+	// Elapsed is the time which passed from the deadline until the error was
+	// observed.
+	// The cause is context.DeadlineExceeded.
+	DeadlineExceeded struct {
+		Deadline time.Time
+		Elapsed  time.Duration
+	}
+)
+
+// Severity method returns SeverityInfo, because a cancellation is usually
+// requested by a caller and is not worth alerting.
+func (r Canceled) Severity() reasonederror.Severity {
+	return reasonederror.SeverityInfo
+}
+
+// Class method returns ClassTransient.
+func (r Canceled) Class() reasonederror.Class {
+	return reasonederror.ClassTransient
+}
+
+// Severity method returns SeverityWarn.
+func (r DeadlineExceeded) Severity() reasonederror.Severity {
+	return reasonederror.SeverityWarn
+}
+
+// Class method returns ClassTransient.
+func (r DeadlineExceeded) Class() reasonederror.Class {
+	return reasonederror.ClassTransient
+}
+
+// FromContext is a function which creates an Err from the error of the
+// specified context.
+// If the context is canceled, this function returns an Err of which reason is
+// Canceled, and if the deadline of the context passed, this function returns
+// an Err of which reason is DeadlineExceeded.
+// The error of the context is set as the cause, so errors.Is function with
+// context.Canceled or context.DeadlineExceeded holds through the Err.
+// If the context is not done, this function returns Ok.
+func FromContext(ctx context.Context) reasonederror.Err {
+	e := ctx.Err()
+	if e == nil {
+		return reasonederror.Ok()
+	}
+
+	if errors.Is(e, context.DeadlineExceeded) {
+		return reasonederror.NewErr(newDeadlineExceeded(ctx), e)
+	}
+
+	return reasonederror.NewErr(Canceled{}, e)
+}
+
+func newDeadlineExceeded(ctx context.Context) DeadlineExceeded {
+	var r DeadlineExceeded
+	if dl, ok := ctx.Deadline(); ok {
+		r.Deadline = dl
+		if elapsed := time.Since(dl); elapsed > 0 {
+			r.Elapsed = elapsed
+		}
+	}
+	return r
+}
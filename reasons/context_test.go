@@ -0,0 +1,72 @@
+package reasons_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/reasons"
+)
+
+func TestFromContext_notDone(t *testing.T) {
+	err := reasons.FromContext(context.Background())
+	assert.True(t, err.IsOk())
+}
+
+func TestFromContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := reasons.FromContext(ctx)
+	switch err.Reason().(type) {
+	case reasons.Canceled:
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, err.Severity(), reasonederror.SeverityInfo)
+	assert.True(t, err.IsTransient())
+}
+
+func TestFromContext_deadlineExceeded(t *testing.T) {
+	deadline := time.Now().Add(-time.Second)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	err := reasons.FromContext(ctx)
+	switch r := err.Reason().(type) {
+	case reasons.DeadlineExceeded:
+		assert.True(t, r.Deadline.Equal(deadline))
+		assert.True(t, r.Elapsed >= time.Second)
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.False(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, err.Severity(), reasonederror.SeverityWarn)
+	assert.True(t, err.IsTransient())
+}
+
+func TestFromContext_filteredBySeverity(t *testing.T) {
+	var names []string
+	handler := reasonederror.FilterBySeverity(reasonederror.SeverityError,
+		func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+			names = append(names, err.ReasonName())
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	handler(reasons.FromContext(ctx), reasonederror.ErrOccasion{})
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	handler(reasons.FromContext(ctx), reasonederror.ErrOccasion{})
+
+	assert.Equal(t, len(names), 0)
+}
@@ -0,0 +1,211 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package recent
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sttk/reasonederror/jsonlines"
+)
+
+// Count is a struct which represents the number of notifications of a reason.
+type Count struct {
+	Reason  string `json:"reason"`
+	Package string `json:"package"`
+	Count   int    `json:"count"`
+}
+
+// Page is a struct which is the content of the debug page.
+// Errs are the records of notifications from the newest, and Counts are the
+// numbers of them per reason in descending order.
+type Page struct {
+	Counts []Count            `json:"counts"`
+	Errs   []jsonlines.Record `json:"errs"`
+}
+
+// Filter is a struct which selects notifications on the debug page.
+// Empty fields do not restrict notifications.
+type Filter struct {
+	Reason  string
+	Package string
+	Since   time.Time
+	Until   time.Time
+}
+
+// ServeHTTP method serves the debug page of the kept notifications.
+// The page is HTML, or JSON if the query parameter "format" is "json" or the
+// Accept header prefers "application/json".
+//
+// The notifications are filtered with the following query parameters:
+//
+//   - reason: the name of the reason type,
+//   - package: the package path of the reason type,
+//   - since: the RFC 3339 time from which notifications are shown,
+//   - until: the RFC 3339 time until which notifications are shown.
+//
+// The situations of Err(s) on the page are redacted.
+func (r *RecentErrs) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, ok := parseFilter(req)
+	if !ok {
+		http.Error(w, "invalid time in since or until", http.StatusBadRequest)
+		return
+	}
+
+	page := r.Page(filter)
+
+	h := w.Header()
+	h.Set("Cache-Control", "no-store")
+	h.Set("X-Content-Type-Options", "nosniff")
+
+	if wantsJSON(req) {
+		h.Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+		return
+	}
+
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	pageTemplate.Execute(w, struct {
+		Page
+		Filter Filter
+	}{page, filter})
+}
+
+// Page method returns the content of the debug page with the notifications
+// selected by the specified Filter.
+func (r *RecentErrs) Page(filter Filter) Page {
+	page := Page{
+		Counts: []Count{},
+		Errs:   []jsonlines.Record{},
+	}
+	counts := make(map[[2]string]int)
+
+	for _, ent := range r.Entries() {
+		if !filter.match(ent) {
+			continue
+		}
+		page.Errs = append(page.Errs, jsonlines.NewRecord(ent.Err, ent.Occasion))
+		counts[[2]string{ent.Err.ReasonPackage(), ent.Err.ReasonName()}]++
+	}
+
+	for k, n := range counts {
+		page.Counts = append(page.Counts, Count{Package: k[0], Reason: k[1], Count: n})
+	}
+	sort.Slice(page.Counts, func(i, j int) bool {
+		a, b := page.Counts[i], page.Counts[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Reason < b.Reason
+	})
+
+	return page
+}
+
+func (f Filter) match(ent Entry) bool {
+	if f.Reason != "" && ent.Err.ReasonName() != f.Reason {
+		return false
+	}
+	if f.Package != "" && ent.Err.ReasonPackage() != f.Package {
+		return false
+	}
+	t := ent.Occasion.Time()
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && t.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+func parseFilter(req *http.Request) (Filter, bool) {
+	q := req.URL.Query()
+
+	f := Filter{
+		Reason:  q.Get("reason"),
+		Package: q.Get("package"),
+	}
+
+	var e error
+	if s := q.Get("since"); s != "" {
+		if f.Since, e = time.Parse(time.RFC3339, s); e != nil {
+			return f, false
+		}
+	}
+	if s := q.Get("until"); s != "" {
+		if f.Until, e = time.Parse(time.RFC3339, s); e != nil {
+			return f, false
+		}
+	}
+	return f, true
+}
+
+func wantsJSON(req *http.Request) bool {
+	if format := req.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	accept := req.Header.Get("Accept")
+	return strings.HasPrefix(accept, "application/json")
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"rfc3339": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Recent errors</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; text-align: left; vertical-align: top; }
+td.num { text-align: right; }
+code { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>Recent errors</h1>
+<form method="get">
+reason <input name="reason" value="{{.Filter.Reason}}">
+package <input name="package" value="{{.Filter.Package}}">
+since <input name="since" value="{{rfc3339 .Filter.Since}}">
+until <input name="until" value="{{rfc3339 .Filter.Until}}">
+<input type="submit" value="filter">
+<a href="?format=json&amp;reason={{.Filter.Reason}}&amp;package={{.Filter.Package}}&amp;since={{rfc3339 .Filter.Since}}&amp;until={{rfc3339 .Filter.Until}}">json</a>
+</form>
+<h2>Counts</h2>
+<table>
+<tr><th>count</th><th>reason</th><th>package</th></tr>
+{{range .Counts}}<tr><td class="num">{{.Count}}</td><td><a href="?reason={{.Reason}}&amp;package={{.Package}}">{{.Reason}}</a></td><td>{{.Package}}</td></tr>
+{{end}}</table>
+<h2>Errors</h2>
+<table>
+<tr><th>time</th><th>severity</th><th>reason</th><th>package</th><th>situation</th><th>causes</th><th>file:line</th></tr>
+{{range .Errs}}<tr><td>{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}</td><td>{{.Severity}}</td><td>{{.Reason}}</td><td>{{.Package}}</td><td><code>{{range $k, $v := .Situation}}{{$k}}={{printf "%s" $v}}
+{{end}}</code></td><td><code>{{range .Causes}}{{template "cause" .}}
+{{end}}</code></td><td>{{.File}}:{{.Line}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+{{define "cause"}}{{if .Reason}}{{.Reason}} ({{.Package}}){{else if .Errs}}[{{range $i, $chain := .Errs}}{{if $i}}, {{end}}{{range $j, $c := $chain}}{{if $j}} &lt; {{end}}{{template "cause" $c}}{{end}}{{end}}]{{else}}{{.Error}}{{end}}{{end}}`))
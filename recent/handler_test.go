@@ -0,0 +1,142 @@
+package recent_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/recent"
+)
+
+func newRecentErrs() (*recent.RecentErrs, []time.Time) {
+	r := recent.NewRecentErrs(10)
+	var times []time.Time
+
+	add := func(err reasonederror.Err) {
+		r.Handle(err, lastOcc)
+		times = append(times, lastOcc.Time())
+		time.Sleep(2 * time.Millisecond)
+	}
+	add(reasonederror.NewErr(FailToConnect{Host: "a"}))
+	add(reasonederror.NewErr(FailToLogin{User: "<b>bob</b>", Password: "secret"}))
+	add(reasonederror.NewErr(FailToConnect{Host: "c"}))
+	return r, times
+}
+
+func getPage(t *testing.T, h http.Handler, query string) recent.Page {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/errs?format=json&"+query, nil))
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/json")
+
+	var page recent.Page
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	return page
+}
+
+func TestRecentErrs_ServeHTTP_json(t *testing.T) {
+	r, _ := newRecentErrs()
+
+	page := getPage(t, r, "")
+	assert.Equal(t, len(page.Errs), 3)
+	assert.Equal(t, page.Errs[0].Reason, "FailToConnect")
+	assert.Equal(t, string(page.Errs[0].Situation["Host"]), `"c"`)
+	assert.Equal(t, page.Errs[1].Reason, "FailToLogin")
+	assert.Equal(t, string(page.Errs[1].Situation["Password"]), `"[REDACTED]"`)
+	assert.Equal(t, page.Counts, []recent.Count{
+		{Reason: "FailToConnect", Package: "github.com/sttk/reasonederror/recent_test", Count: 2},
+		{Reason: "FailToLogin", Package: "github.com/sttk/reasonederror/recent_test", Count: 1},
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/debug/errs", nil)
+	req.Header.Set("Accept", "application/json")
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "application/json")
+}
+
+func TestRecentErrs_ServeHTTP_filter(t *testing.T) {
+	r, times := newRecentErrs()
+
+	page := getPage(t, r, "reason=FailToLogin")
+	assert.Equal(t, len(page.Errs), 1)
+	assert.Equal(t, page.Errs[0].Reason, "FailToLogin")
+	assert.Equal(t, len(page.Counts), 1)
+
+	page = getPage(t, r, "package=github.com/other")
+	assert.Equal(t, len(page.Errs), 0)
+	assert.Equal(t, len(page.Counts), 0)
+
+	page = getPage(t, r, "package=github.com/sttk/reasonederror/recent_test")
+	assert.Equal(t, len(page.Errs), 3)
+
+	page = r.Page(recent.Filter{Since: times[1]})
+	assert.Equal(t, len(page.Errs), 2)
+	page = r.Page(recent.Filter{Until: times[1]})
+	assert.Equal(t, len(page.Errs), 2)
+	page = r.Page(recent.Filter{Since: times[1], Until: times[1]})
+	assert.Equal(t, len(page.Errs), 1)
+
+	since := times[2].Add(time.Second).Format(time.RFC3339)
+	page = getPage(t, r, "since="+url.QueryEscape(since))
+	assert.Equal(t, len(page.Errs), 0)
+
+	until := times[0].Add(-time.Second).Format(time.RFC3339)
+	page = getPage(t, r, "until="+url.QueryEscape(until))
+	assert.Equal(t, len(page.Errs), 0)
+}
+
+func TestRecentErrs_ServeHTTP_html(t *testing.T) {
+	r, _ := newRecentErrs()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/errs?reason=%3Cscript%3E", nil))
+	assert.Equal(t, rec.Code, http.StatusOK)
+	assert.Equal(t, rec.Header().Get("Content-Type"), "text/html; charset=utf-8")
+	assert.Equal(t, rec.Header().Get("Cache-Control"), "no-store")
+	assert.NotContains(t, rec.Body.String(), "<script>")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/errs", nil))
+	body := rec.Body.String()
+	assert.True(t, strings.Contains(body, "<td>FailToLogin</td>"))
+	assert.True(t, strings.Contains(body, `User=&#34;\u003cb\u003ebob\u003c/b\u003e&#34;`))
+	assert.True(t, strings.Contains(body, "Password=&#34;[REDACTED]&#34;"))
+	assert.False(t, strings.Contains(body, "secret"))
+	assert.True(t, strings.Contains(body, `<td class="num">2</td>`))
+}
+
+func TestRecentErrs_ServeHTTP_htmlErrsCause(t *testing.T) {
+	r := recent.NewRecentErrs(10)
+	errs := reasonederror.Errs{
+		reasonederror.NewErr(FailToLogin{User: "bob", Password: "secret"}),
+		reasonederror.NewErr(FailToConnect{Host: "a"}),
+	}
+	r.Handle(reasonederror.NewErr(FailToConnect{Host: "b"}, errs), lastOcc)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/errs", nil))
+	body := rec.Body.String()
+	assert.True(t, strings.Contains(body,
+		"[FailToLogin (github.com/sttk/reasonederror/recent_test), FailToConnect (github.com/sttk/reasonederror/recent_test)]"), body)
+	assert.False(t, strings.Contains(body, "secret"))
+}
+
+func TestRecentErrs_ServeHTTP_badRequest(t *testing.T) {
+	r, _ := newRecentErrs()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/errs?since=yesterday", nil))
+	assert.Equal(t, rec.Code, http.StatusBadRequest)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("POST", "/debug/errs", nil))
+	assert.Equal(t, rec.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, rec.Header().Get("Allow"), "GET, HEAD")
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package recent provides an Err notification handler which keeps the recent
+// notifications in memory, and serves them on a debug page like /debug/pprof.
+//
+//	recentErrs := recent.NewRecentErrs(1000)
+//	reasonederror.AddAsyncErrHandler(recentErrs.Handle)
+//	reasonederror.FixErrCfgs()
+//
+//	mux.Handle("/debug/errs", auth(recentErrs))
+//
+// The page does not authenticate requests, so it should be mounted behind an
+// authentication of an application.
+package recent
+
+import (
+	"sync/atomic"
+
+	"github.com/sttk/reasonederror"
+)
+
+// Entry is a struct which holds an Err notification.
+// Seq is the sequence number of the notification, starting from zero.
+type Entry struct {
+	Seq      uint64
+	Err      reasonederror.Err
+	Occasion reasonederror.ErrOccasion
+}
+
+// RecentErrs is a ring buffer which keeps the last notifications of Err(s).
+// Handle method does not take a lock, so it is suitable for a synchronous
+// handler too.
+type RecentErrs struct {
+	next  uint64 // accessed atomically, placed first for 64-bit alignment
+	slots []atomic.Value
+}
+
+// NewRecentErrs is a function which creates a RecentErrs which keeps the
+// specified number of the last notifications.
+// If the size is zero or less, 100 is used.
+func NewRecentErrs(size int) *RecentErrs {
+	if size <= 0 {
+		size = 100
+	}
+	return &RecentErrs{slots: make([]atomic.Value, size)}
+}
+
+// Handle method stores the specified Err and ErrOccasion, overwriting the
+// oldest one if the buffer is full.
+// This method is used as an Err notification handler.
+func (r *RecentErrs) Handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	seq := atomic.AddUint64(&r.next, 1) - 1
+	ent := &Entry{
+		Seq:      seq,
+		Err:      err,
+		Occasion: occ,
+	}
+
+	slot := &r.slots[seq%uint64(len(r.slots))]
+	for {
+		old := slot.Load()
+		// A slow writer of an older entry must not overwrite a newer entry
+		// which has already been stored in the same slot.
+		if old != nil && old.(*Entry).Seq > seq {
+			return
+		}
+		if slot.CompareAndSwap(old, ent) {
+			return
+		}
+	}
+}
+
+// Entries method returns the kept notifications from the newest.
+// A notification which is being stored concurrently is skipped.
+func (r *RecentErrs) Entries() []Entry {
+	next := atomic.LoadUint64(&r.next)
+	size := uint64(len(r.slots))
+
+	n := next
+	if n > size {
+		n = size
+	}
+
+	entries := make([]Entry, 0, n)
+	for i := uint64(1); i <= n; i++ {
+		seq := next - i
+		v := r.slots[seq%size].Load()
+		if v == nil {
+			continue
+		}
+		ent := v.(*Entry)
+		if ent.Seq != seq {
+			continue
+		}
+		entries = append(entries, *ent)
+	}
+	return entries
+}
@@ -0,0 +1,102 @@
+package recent_test
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/recent"
+)
+
+type /* error reasons */ (
+	FailToConnect struct {
+		Host string
+	}
+	FailToLogin struct {
+		User     string
+		Password string `redact:"true"`
+	}
+)
+
+var lastOcc reasonederror.ErrOccasion
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastOcc = occ
+	})
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+func hosts(entries []recent.Entry) []string {
+	a := make([]string, len(entries))
+	for i, ent := range entries {
+		a[i] = ent.Err.Get("Host").(string)
+	}
+	return a
+}
+
+func TestRecentErrs(t *testing.T) {
+	r := recent.NewRecentErrs(3)
+	assert.Equal(t, len(r.Entries()), 0)
+
+	r.Handle(reasonederror.NewErr(FailToConnect{Host: "a"}), lastOcc)
+	r.Handle(reasonederror.NewErr(FailToConnect{Host: "b"}), lastOcc)
+	assert.Equal(t, hosts(r.Entries()), []string{"b", "a"})
+
+	r.Handle(reasonederror.NewErr(FailToConnect{Host: "c"}), lastOcc)
+	r.Handle(reasonederror.NewErr(FailToConnect{Host: "d"}), lastOcc)
+	r.Handle(reasonederror.NewErr(FailToConnect{Host: "e"}), lastOcc)
+
+	entries := r.Entries()
+	assert.Equal(t, hosts(entries), []string{"e", "d", "c"})
+	assert.Equal(t, entries[0].Seq, uint64(4))
+	assert.Equal(t, entries[2].Seq, uint64(2))
+	assert.Equal(t, entries[0].Occasion, lastOcc)
+}
+
+func TestNewRecentErrs_defaultSize(t *testing.T) {
+	r := recent.NewRecentErrs(0)
+	err := reasonederror.NewErr(FailToConnect{Host: "a"})
+	for i := 0; i < 150; i++ {
+		r.Handle(err, lastOcc)
+	}
+	assert.Equal(t, len(r.Entries()), 100)
+}
+
+func TestRecentErrs_concurrent(t *testing.T) {
+	r := recent.NewRecentErrs(50)
+	err := reasonederror.NewErr(FailToConnect{Host: "a"})
+	occ := lastOcc
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				r.Handle(err, occ)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				entries := r.Entries()
+				for k := 1; k < len(entries); k++ {
+					assert.True(t, entries[k-1].Seq > entries[k].Seq)
+					assert.True(t, entries[k-1].Seq-entries[k].Seq < 50)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries := r.Entries()
+	assert.Equal(t, len(entries), 50)
+	for k, ent := range entries {
+		assert.Equal(t, ent.Seq, uint64(799-k))
+	}
+}
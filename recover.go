@@ -0,0 +1,116 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+type /* error reasons */ (
+	// Panicked is an error reason which indicates that a panic occurred.
+	// Value is the value passed to panic, and Stack is the stack trace of the
+	// goroutine at the panic.
+	// If the value is an error, it is set as the cause.
+	Panicked struct {
+		Value interface{} `redact:"true"`
+		Stack string      `redact:"true"`
+	}
+)
+
+// Recover is a function which recovers a panic and sets an Err of which
+// reason is Panicked to the specified pointer.
+// This function must be called directly with defer statement:
+//
+//	func doSomething() (err reasonederror.Err) {
+//	    defer reasonederror.Recover(&err)
+//	    ...
+//	}
+//
+// The created Err is notified to the Err creation event handlers, and the
+// ErrOccasion points to the position where the panic occurred.
+// If no panic occurred, this function does nothing.
+func Recover(err *Err) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	e := newPanickedErr(v)
+	if err != nil {
+		*err = e
+	}
+}
+
+// Try is a function which executes the specified function and returns its
+// result.
+// If the function panics, this function recovers it and returns an Err of
+// which reason is Panicked, like Recover function.
+func Try(fn func() Err) (err Err) {
+	defer Recover(&err)
+	return fn()
+}
+
+// NewErrAtPanic is a function which creates a new Err with a specified reason
+// and an optional cause, like NewErr function, but the ErrOccasion notified to
+// the Err creation event handlers points to the position where the current
+// panic occurred.
+// This function is used to convert a recovered panic into an Err with a
+// reason other than Panicked, and should be called in a deferred function
+// while panicking.
+// If no panic is occurring, the ErrOccasion points to the position where this
+// function is called.
+func NewErrAtPanic(reason interface{}, cause ...error) Err {
+	var err Err
+	err.reason = reason
+
+	if len(cause) > 0 {
+		err.cause = cause[0]
+	}
+
+	if isErrNotifiable() {
+		pc, file, line, ok := panicCaller()
+		if !ok {
+			pc, file, line, ok = runtime.Caller(1)
+		}
+		notifyErrWithOccasion(err, newErrOccasion(pc, file, line, ok))
+	}
+
+	return err
+}
+
+func newPanickedErr(v interface{}) Err {
+	reason := Panicked{Value: v, Stack: string(debug.Stack())}
+
+	if e, ok := v.(error); ok {
+		return NewErrAtPanic(reason, e)
+	}
+	return NewErrAtPanic(reason)
+}
+
+// panicCaller returns the position of the function which caused the current
+// panic, by searching the first frame which is not in the runtime package
+// after the frame of runtime.gopanic.
+func panicCaller() (uintptr, string, int, bool) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	isAfterPanic := false
+	for {
+		f, more := frames.Next()
+		if f.Function == "runtime.gopanic" {
+			isAfterPanic = true
+		} else if isAfterPanic && !strings.HasPrefix(f.Function, "runtime.") {
+			return f.PC, f.File, f.Line, true
+		}
+		if !more {
+			break
+		}
+	}
+
+	return 0, "", 0, false
+}
@@ -0,0 +1,199 @@
+package reasonederror
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var panicLine int
+
+func panicWith(v interface{}) Err {
+	_, _, line, _ := runtime.Caller(0)
+	panicLine = line + 2
+	panic(v)
+}
+
+func dereferenceNil() Err {
+	var p *struct{ n int }
+	_, _, line, _ := runtime.Caller(0)
+	panicLine = line + 2
+	p.n++
+	return Ok()
+}
+
+func recoverFrom(fn func() Err) (err Err) {
+	defer Recover(&err)
+	return fn()
+}
+
+func TestRecover_panicWithValue(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	err := recoverFrom(func() Err {
+		return panicWith("boom")
+	})
+
+	assert.True(t, err.IsNotOk())
+	switch r := err.Reason().(type) {
+	case Panicked:
+		assert.Equal(t, r.Value, "boom")
+		assert.True(t, strings.Contains(r.Stack, "panicWith"))
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Nil(t, err.Cause())
+}
+
+func TestRecover_panicWithError(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	cause := errors.New("boom")
+	err := recoverFrom(func() Err {
+		return panicWith(cause)
+	})
+
+	assert.Equal(t, err.ReasonName(), "Panicked")
+	assert.Equal(t, err.Get("Value"), cause)
+	assert.Equal(t, err.Cause(), cause)
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestRecover_runtimeError(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	err := recoverFrom(dereferenceNil)
+
+	assert.Equal(t, err.ReasonName(), "Panicked")
+	var re runtime.Error
+	assert.True(t, errors.As(err, &re))
+}
+
+func TestRecover_noPanic(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	err := recoverFrom(func() Err {
+		return NewErr(ReasonForNotification{})
+	})
+	assert.Equal(t, err.ReasonName(), "ReasonForNotification")
+
+	err = recoverFrom(Ok)
+	assert.True(t, err.IsOk())
+}
+
+func TestRecover_nilPointer(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	assert.NotPanics(t, func() {
+		defer Recover(nil)
+		panic("boom")
+	})
+}
+
+func TestRecover_notification(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	var occs []ErrOccasion
+	var errs []Err
+	AddSyncErrHandler(func(err Err, occ ErrOccasion) {
+		errs = append(errs, err)
+		occs = append(occs, occ)
+	})
+	FixErrCfgs()
+
+	recoverFrom(func() Err {
+		return panicWith("boom")
+	})
+	line1 := panicLine
+
+	recoverFrom(dereferenceNil)
+	line2 := panicLine
+
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs[0].ReasonName(), "Panicked")
+	assert.Equal(t, occs[0].File(), "recover_test.go")
+	assert.Equal(t, occs[0].Line(), line1)
+	assert.Equal(t, errs[1].ReasonName(), "Panicked")
+	assert.Equal(t, occs[1].File(), "recover_test.go")
+	assert.Equal(t, occs[1].Line(), line2)
+}
+
+func TestTry(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	err := Try(func() Err {
+		return panicWith("boom")
+	})
+	assert.Equal(t, err.ReasonName(), "Panicked")
+	assert.Equal(t, err.Get("Value"), "boom")
+
+	err = Try(func() Err {
+		return NewErr(ReasonForNotification{})
+	})
+	assert.Equal(t, err.ReasonName(), "ReasonForNotification")
+
+	err = Try(Ok)
+	assert.True(t, err.IsOk())
+}
+
+func TestTry_inGoroutine(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	ch := make(chan Err)
+	go func() {
+		ch <- Try(func() Err {
+			var m map[string]int
+			m["a"] = 1
+			return Ok()
+		})
+	}()
+
+	err := <-ch
+	assert.Equal(t, err.ReasonName(), "Panicked")
+	assert.NotNil(t, err.Cause())
+}
+
+func TestNewErrAtPanic(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	var occs []ErrOccasion
+	var errs []Err
+	AddSyncErrHandler(func(err Err, occ ErrOccasion) {
+		errs = append(errs, err)
+		occs = append(occs, occ)
+	})
+	FixErrCfgs()
+
+	cause := errors.New("boom")
+	func() {
+		defer func() {
+			NewErrAtPanic(ReasonForNotification{}, recover().(error))
+		}()
+		panicWith(cause)
+	}()
+	line1 := panicLine
+
+	_, _, line2, _ := runtime.Caller(0)
+	err := NewErrAtPanic(ReasonForNotification{})
+
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs[0].ReasonName(), "ReasonForNotification")
+	assert.Equal(t, errs[0].Cause(), cause)
+	assert.Equal(t, occs[0].File(), "recover_test.go")
+	assert.Equal(t, occs[0].Line(), line1)
+	assert.Equal(t, errs[1], err)
+	assert.Equal(t, occs[1].File(), "recover_test.go")
+	assert.Equal(t, occs[1].Line(), line2+1)
+}
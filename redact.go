@@ -0,0 +1,63 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"reflect"
+)
+
+// RedactedValue is a string which replaces values of redacted fields.
+const RedactedValue = "[REDACTED]"
+
+// RedactedSituation method returns a map like Situation method, but the values
+// of reason struct fields tagged with `redact:"true"` are replaced with
+// RedactedValue.
+// This method is useful to show a situation outside of an application, like
+// in an HTTP response.
+//
+//	type FailToLogin struct {
+//	    User     string
+//	    Password string `redact:"true"`
+//	}
+func (err Err) RedactedSituation() map[string]interface{} {
+	var m map[string]interface{}
+
+	if err.reason == nil {
+		return m
+	}
+
+	v := reflect.ValueOf(err.reason)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if cause, ok := err.cause.(Err); ok {
+		m = cause.RedactedSituation()
+	}
+
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+
+	t := v.Type()
+
+	n := v.NumField()
+	for i := 0; i < n; i++ {
+		sf := t.Field(i)
+
+		f := v.Field(i)
+		if !f.CanInterface() {
+			continue
+		}
+
+		if sf.Tag.Get("redact") == "true" {
+			m[sf.Name] = RedactedValue
+		} else {
+			m[sf.Name] = f.Interface()
+		}
+	}
+
+	return m
+}
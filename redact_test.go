@@ -0,0 +1,65 @@
+package reasonederror_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	FailToLogin struct {
+		User     string
+		Password string `redact:"true"`
+		Token    string `redact:"false"`
+		secret   string
+	}
+	FailToAuthorize struct {
+		Role   string
+		APIKey string `redact:"true"`
+	}
+)
+
+func TestErr_RedactedSituation(t *testing.T) {
+	err := re.NewErr(FailToLogin{
+		User: "alice", Password: "p@ss", Token: "t0k", secret: "s",
+	})
+
+	m := err.RedactedSituation()
+	assert.Equal(t, len(m), 3)
+	assert.Equal(t, m["User"], "alice")
+	assert.Equal(t, m["Password"], re.RedactedValue)
+	assert.Equal(t, m["Token"], "t0k")
+
+	m = err.Situation()
+	assert.Equal(t, m["Password"], "p@ss")
+}
+
+func TestErr_RedactedSituation_causeIsAlsoErr(t *testing.T) {
+	cause := re.NewErr(&FailToLogin{User: "alice", Password: "p@ss"})
+	err := re.NewErr(FailToAuthorize{Role: "admin", APIKey: "k"}, cause)
+
+	m := err.RedactedSituation()
+	assert.Equal(t, len(m), 5)
+	assert.Equal(t, m["User"], "alice")
+	assert.Equal(t, m["Password"], re.RedactedValue)
+	assert.Equal(t, m["Token"], "")
+	assert.Equal(t, m["Role"], "admin")
+	assert.Equal(t, m["APIKey"], re.RedactedValue)
+}
+
+func TestErr_RedactedSituation_causeIsNotErr(t *testing.T) {
+	err := re.NewErr(FailToAuthorize{Role: "admin", APIKey: "k"}, errors.New("x"))
+
+	m := err.RedactedSituation()
+	assert.Equal(t, len(m), 2)
+	assert.Equal(t, m["Role"], "admin")
+	assert.Equal(t, m["APIKey"], re.RedactedValue)
+}
+
+func TestErr_RedactedSituation_ok(t *testing.T) {
+	m := re.Ok().RedactedSituation()
+	assert.Nil(t, m)
+	assert.Equal(t, len(m), 0)
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+// Result is a struct which holds a value and an Err, which is the result of
+// a function that returns a value or fails.
+type Result[T any] struct {
+	value T
+	err   Err
+}
+
+// OkResult is a function which creates a Result with a value and no error.
+func OkResult[T any](value T) Result[T] {
+	return Result[T]{value: value, err: ok}
+}
+
+// ErrResult is a function which creates a Result with an Err and the zero
+// value.
+func ErrResult[T any](err Err) Result[T] {
+	return Result[T]{err: err}
+}
+
+// ResultOf is a function which creates a Result from a value and an Err,
+// typically returned by a function.
+func ResultOf[T any](value T, err Err) Result[T] {
+	return Result[T]{value: value, err: err}
+}
+
+// IsOk method checks whether this Result has no error.
+func (r Result[T]) IsOk() bool {
+	return r.err.IsOk()
+}
+
+// IsNotOk method checks whether this Result has an error.
+func (r Result[T]) IsNotOk() bool {
+	return r.err.IsNotOk()
+}
+
+// Value method returns the value of this Result.
+func (r Result[T]) Value() T {
+	return r.value
+}
+
+// Err method returns the Err of this Result.
+func (r Result[T]) Err() Err {
+	return r.err
+}
+
+// Unwrap method returns the value and the Err of this Result.
+func (r Result[T]) Unwrap() (T, Err) {
+	return r.value, r.err
+}
+
+// UnwrapOr method returns the value of this Result if this Result has no
+// error, or the specified default value otherwise.
+func (r Result[T]) UnwrapOr(def T) T {
+	if r.err.IsNotOk() {
+		return def
+	}
+	return r.value
+}
+
+// Map is a function which converts the value of a Result with an argument
+// function if the Result has no error.
+// If the Result has an error, this function returns a Result with the Err.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err.IsNotOk() {
+		return Result[U]{err: r.err}
+	}
+	return Result[U]{value: fn(r.value), err: ok}
+}
+
+// AndThen is a function which executes an argument function with the value
+// of a Result if the Result has no error, and returns the result of the
+// function.
+// If the Result has an error, this function returns a Result with the Err.
+func AndThen[T, U any](r Result[T], fn func(T) Result[U]) Result[U] {
+	if r.err.IsNotOk() {
+		return Result[U]{err: r.err}
+	}
+	return fn(r.value)
+}
@@ -0,0 +1,93 @@
+package reasonederror_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+func parseInt(s string) re.Result[int] {
+	n, e := strconv.Atoi(s)
+	if e != nil {
+		return re.ErrResult[int](re.NewErr(InvalidValue{Value: s}, e))
+	}
+	return re.OkResult(n)
+}
+
+func TestOkResult(t *testing.T) {
+	r := re.OkResult(123)
+	assert.True(t, r.IsOk())
+	assert.False(t, r.IsNotOk())
+	assert.Equal(t, r.Value(), 123)
+	assert.True(t, r.Err().IsOk())
+	assert.Equal(t, r.UnwrapOr(9), 123)
+
+	v, err := r.Unwrap()
+	assert.Equal(t, v, 123)
+	assert.True(t, err.IsOk())
+}
+
+func TestErrResult(t *testing.T) {
+	r := re.ErrResult[string](re.NewErr(InvalidValue{Value: "x"}))
+	assert.False(t, r.IsOk())
+	assert.True(t, r.IsNotOk())
+	assert.Equal(t, r.Value(), "")
+	assert.Equal(t, r.Err().ReasonName(), "InvalidValue")
+	assert.Equal(t, r.UnwrapOr("def"), "def")
+
+	v, err := r.Unwrap()
+	assert.Equal(t, v, "")
+	assert.Equal(t, err.ReasonName(), "InvalidValue")
+}
+
+func TestResultOf(t *testing.T) {
+	r := re.ResultOf(1.5, re.Ok())
+	assert.True(t, r.IsOk())
+	assert.Equal(t, r.Value(), 1.5)
+
+	r = re.ResultOf(0.0, re.NewErr(InvalidValue{}))
+	assert.True(t, r.IsNotOk())
+}
+
+func TestMap(t *testing.T) {
+	r := re.Map(parseInt("12"), func(n int) string {
+		return strconv.Itoa(n * 2)
+	})
+	assert.True(t, r.IsOk())
+	assert.Equal(t, r.Value(), "24")
+
+	executed := false
+	r = re.Map(parseInt("a"), func(n int) string {
+		executed = true
+		return ""
+	})
+	assert.False(t, executed)
+	assert.Equal(t, r.Err().ReasonName(), "InvalidValue")
+	assert.Equal(t, r.Err().Get("Value"), "a")
+}
+
+func TestAndThen(t *testing.T) {
+	validate := func(n int) re.Result[int] {
+		if n < 0 {
+			return re.ErrResult[int](re.NewErr(InvalidValue{Value: strconv.Itoa(n)}))
+		}
+		return re.OkResult(n)
+	}
+
+	r := re.AndThen(parseInt("12"), validate)
+	assert.True(t, r.IsOk())
+	assert.Equal(t, r.Value(), 12)
+
+	r = re.AndThen(parseInt("-3"), validate)
+	assert.Equal(t, r.Err().Get("Value"), "-3")
+
+	executed := false
+	r = re.AndThen(parseInt("a"), func(n int) re.Result[int] {
+		executed = true
+		return re.OkResult(n)
+	})
+	assert.False(t, executed)
+	assert.Equal(t, r.Err().Get("Value"), "a")
+}
@@ -0,0 +1,196 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type /* error reasons */ (
+	// RetriesExhausted is an error reason which indicates that an operation
+	// failed in all attempts by Retry function.
+	// The Err of the last attempt is set as the cause.
+	RetriesExhausted struct {
+		Attempts int
+	}
+)
+
+const (
+	defaultMaxAttempts     = 3
+	defaultInitialInterval = 100 * time.Millisecond
+	defaultMultiplier      = 2.0
+)
+
+// RetryPolicy is a struct which configures the behavior of Retry function.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one.
+	// If this is zero or less, 3 is used.
+	MaxAttempts int
+
+	// InitialInterval is the wait time before the second attempt.
+	// If this is zero or less, 100 milliseconds is used.
+	InitialInterval time.Duration
+
+	// MaxInterval is the upper limit of the wait time between attempts.
+	// If this is zero or less, the wait time is limited only by the maximum
+	// value of time.Duration.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor by which the wait time grows at each attempt.
+	// If this is less than 1, 2 is used.
+	Multiplier float64
+
+	// Jitter is the randomization factor of the wait time, which ranges from
+	// 0 to 1. A wait time d is randomized within [d*(1-Jitter), d*(1+Jitter)].
+	Jitter float64
+
+	// Now is the function which returns the current time.
+	// If this is nil, time.Now is used.
+	Now func() time.Time
+
+	// Sleep is the function which waits for the specified duration or until
+	// the context is done.
+	// If this is nil, a function using time.Timer is used.
+	Sleep func(ctx context.Context, d time.Duration) error
+
+	// Rand is the function which returns a pseudo-random number in [0, 1) for
+	// the jitter.
+	// If this is nil, rand.Float64 is used.
+	Rand func() float64
+}
+
+// RetryAfter method returns a wait time hint before retrying an operation
+// which caused this Err.
+// The hint is read from the situation field named "RetryAfter", which is
+// either a time.Duration or a time.Time.
+// If there is no such field, the second result is false.
+func (err Err) RetryAfter() (time.Duration, bool) {
+	return retryAfter(err, time.Now)
+}
+
+func retryAfter(err Err, now func() time.Time) (time.Duration, bool) {
+	switch v := err.Get("RetryAfter").(type) {
+	case time.Duration:
+		return v, true
+	case time.Time:
+		if v.IsZero() {
+			return 0, false
+		}
+		d := v.Sub(now())
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	default:
+		return 0, false
+	}
+}
+
+// Retry is a function which executes the specified function repeatedly while
+// it returns a retryable Err.
+// The wait time between attempts grows exponentially with the policy, but if
+// an Err has a RetryAfter hint, the hint is used instead.
+//
+// This function returns Ok or a non-retryable Err as it is.
+// If the function fails in all attempts, this function returns an Err of
+// which reason is RetriesExhausted and cause is the Err of the last attempt.
+// If the context is done while waiting, this function returns the Err of the
+// last attempt.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() Err) Err {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	now := policy.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	sleep := policy.Sleep
+	if sleep == nil {
+		sleep = sleepWithContext
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if !err.IsRetryable() {
+			return err
+		}
+
+		if attempt >= maxAttempts {
+			return NewErr(RetriesExhausted{Attempts: attempt}, err)
+		}
+
+		d, ok := retryAfter(err, now)
+		if !ok {
+			d = policy.backoff(attempt)
+		}
+
+		if sleep(ctx, d) != nil {
+			return err
+		}
+	}
+}
+
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	interval := policy.InitialInterval
+	if interval <= 0 {
+		interval = defaultInitialInterval
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = defaultMultiplier
+	}
+
+	d := float64(interval)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if policy.MaxInterval > 0 && d >= float64(policy.MaxInterval) {
+			break
+		}
+		if d >= math.MaxInt64 {
+			break
+		}
+	}
+	if policy.MaxInterval > 0 && d > float64(policy.MaxInterval) {
+		d = float64(policy.MaxInterval)
+	}
+
+	if policy.Jitter > 0 {
+		jitter := policy.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		random := policy.Rand
+		if random == nil {
+			random = rand.Float64
+		}
+		d *= 1 - jitter + 2*jitter*random()
+	}
+
+	// A float64 which is equal to or greater than math.MaxInt64 overflows
+	// when converted to time.Duration.
+	if d >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
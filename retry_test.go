@@ -0,0 +1,228 @@
+package reasonederror_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	re "github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	ServiceUnavailable struct {
+		RetryAfter interface{}
+	}
+	ServiceBusy struct{}
+)
+
+func (r ServiceUnavailable) Class() re.Class {
+	return re.ClassTransient | re.ClassRetryable
+}
+
+type fakeSleeper struct {
+	durations []time.Duration
+	err       error
+}
+
+func (s *fakeSleeper) sleep(ctx context.Context, d time.Duration) error {
+	s.durations = append(s.durations, d)
+	return s.err
+}
+
+func TestErr_IsRetryable(t *testing.T) {
+	re.ClearErrHandlers()
+	defer re.ClearErrHandlers()
+
+	assert.True(t, re.NewErr(ServiceUnavailable{}).IsRetryable())
+	assert.False(t, re.NewErr(ServiceBusy{}).IsRetryable())
+	assert.False(t, re.Ok().IsRetryable())
+
+	re.SetReasonAttrs(ServiceBusy{}, re.ReasonAttrs{Class: re.ClassRetryable})
+	assert.True(t, re.NewErr(ServiceBusy{}).IsRetryable())
+}
+
+func TestErr_RetryAfter(t *testing.T) {
+	d, ok := re.NewErr(ServiceUnavailable{RetryAfter: 3 * time.Second}).RetryAfter()
+	assert.True(t, ok)
+	assert.Equal(t, d, 3*time.Second)
+
+	tm := time.Now().Add(time.Hour)
+	d, ok = re.NewErr(ServiceUnavailable{RetryAfter: tm}).RetryAfter()
+	assert.True(t, ok)
+	assert.True(t, d > 59*time.Minute && d <= time.Hour)
+
+	d, ok = re.NewErr(ServiceUnavailable{RetryAfter: time.Now().Add(-time.Hour)}).RetryAfter()
+	assert.True(t, ok)
+	assert.Equal(t, d, time.Duration(0))
+
+	_, ok = re.NewErr(ServiceUnavailable{RetryAfter: time.Time{}}).RetryAfter()
+	assert.False(t, ok)
+
+	_, ok = re.NewErr(ServiceUnavailable{RetryAfter: "3s"}).RetryAfter()
+	assert.False(t, ok)
+
+	_, ok = re.NewErr(ServiceBusy{}).RetryAfter()
+	assert.False(t, ok)
+}
+
+func TestRetry_succeedAtFirst(t *testing.T) {
+	s := &fakeSleeper{}
+	n := 0
+	err := re.Retry(context.Background(), re.RetryPolicy{Sleep: s.sleep}, func() re.Err {
+		n++
+		return re.Ok()
+	})
+	assert.True(t, err.IsOk())
+	assert.Equal(t, n, 1)
+	assert.Equal(t, len(s.durations), 0)
+}
+
+func TestRetry_succeedAfterRetries(t *testing.T) {
+	s := &fakeSleeper{}
+	n := 0
+	err := re.Retry(context.Background(), re.RetryPolicy{
+		MaxAttempts: 5,
+		Sleep:       s.sleep,
+	}, func() re.Err {
+		n++
+		if n < 3 {
+			return re.NewErr(ServiceUnavailable{})
+		}
+		return re.Ok()
+	})
+	assert.True(t, err.IsOk())
+	assert.Equal(t, n, 3)
+	assert.Equal(t, s.durations, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond,
+	})
+}
+
+func TestRetry_notRetryable(t *testing.T) {
+	s := &fakeSleeper{}
+	n := 0
+	err := re.Retry(context.Background(), re.RetryPolicy{Sleep: s.sleep}, func() re.Err {
+		n++
+		return re.NewErr(ServiceBusy{})
+	})
+	assert.Equal(t, err.ReasonName(), "ServiceBusy")
+	assert.Equal(t, n, 1)
+	assert.Equal(t, len(s.durations), 0)
+}
+
+func TestRetry_exhausted(t *testing.T) {
+	s := &fakeSleeper{}
+	n := 0
+	err := re.Retry(context.Background(), re.RetryPolicy{
+		MaxAttempts:     6,
+		InitialInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      3,
+		Sleep:           s.sleep,
+	}, func() re.Err {
+		n++
+		return re.NewErr(ServiceUnavailable{})
+	})
+	assert.Equal(t, n, 6)
+	assert.Equal(t, s.durations, []time.Duration{
+		time.Second, 3 * time.Second, 9 * time.Second, 10 * time.Second,
+		10 * time.Second,
+	})
+
+	switch r := err.Reason().(type) {
+	case re.RetriesExhausted:
+		assert.Equal(t, r.Attempts, 6)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause().(re.Err).ReasonName(), "ServiceUnavailable")
+}
+
+func TestRetry_manyAttemptsWithoutMaxInterval(t *testing.T) {
+	s := &fakeSleeper{}
+	err := re.Retry(context.Background(), re.RetryPolicy{
+		MaxAttempts:     100,
+		InitialInterval: time.Second,
+		Jitter:          0.5,
+		Sleep:           s.sleep,
+		Rand:            func() float64 { return 0.99 },
+	}, func() re.Err {
+		return re.NewErr(ServiceUnavailable{})
+	})
+	assert.Equal(t, err.ReasonName(), "RetriesExhausted")
+	assert.Equal(t, len(s.durations), 99)
+
+	for i := 1; i < len(s.durations); i++ {
+		assert.True(t, s.durations[i] >= s.durations[i-1])
+	}
+	assert.Equal(t, s.durations[98], time.Duration(math.MaxInt64))
+}
+
+func TestRetry_jitter(t *testing.T) {
+	s := &fakeSleeper{}
+	randoms := []float64{0.0, 0.5, 0.99}
+	err := re.Retry(context.Background(), re.RetryPolicy{
+		MaxAttempts: 4,
+		Jitter:      0.5,
+		Sleep:       s.sleep,
+		Rand: func() float64 {
+			r := randoms[0]
+			randoms = randoms[1:]
+			return r
+		},
+	}, func() re.Err {
+		return re.NewErr(ServiceUnavailable{})
+	})
+	assert.Equal(t, err.ReasonName(), "RetriesExhausted")
+	assert.Equal(t, s.durations, []time.Duration{
+		50 * time.Millisecond, 200 * time.Millisecond, 596 * time.Millisecond,
+	})
+}
+
+func TestRetry_retryAfterHint(t *testing.T) {
+	s := &fakeSleeper{}
+	now := time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC)
+	hints := []interface{}{5 * time.Second, now.Add(7 * time.Second), nil, nil}
+	err := re.Retry(context.Background(), re.RetryPolicy{
+		MaxAttempts: 4,
+		Now:         func() time.Time { return now },
+		Sleep:       s.sleep,
+	}, func() re.Err {
+		h := hints[0]
+		hints = hints[1:]
+		return re.NewErr(ServiceUnavailable{RetryAfter: h})
+	})
+	assert.Equal(t, err.ReasonName(), "RetriesExhausted")
+	assert.Equal(t, s.durations, []time.Duration{
+		5 * time.Second, 7 * time.Second, 400 * time.Millisecond,
+	})
+}
+
+func TestRetry_contextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	n := 0
+	err := re.Retry(ctx, re.RetryPolicy{
+		MaxAttempts:     3,
+		InitialInterval: time.Hour,
+	}, func() re.Err {
+		n++
+		cancel()
+		return re.NewErr(ServiceUnavailable{})
+	})
+	assert.Equal(t, n, 1)
+	assert.Equal(t, err.ReasonName(), "ServiceUnavailable")
+}
+
+func TestRetry_defaultSleep(t *testing.T) {
+	n := 0
+	err := re.Retry(context.Background(), re.RetryPolicy{
+		MaxAttempts:     2,
+		InitialInterval: time.Millisecond,
+	}, func() re.Err {
+		n++
+		return re.NewErr(ServiceUnavailable{})
+	})
+	assert.Equal(t, n, 2)
+	assert.Equal(t, err.ReasonName(), "RetriesExhausted")
+}
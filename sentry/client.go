@@ -0,0 +1,194 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package sentry
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// FailToSend is an error reason which indicates that a Client failed to
+	// send some events.
+	// The cause is the first error which occurred.
+	FailToSend struct {
+		Events int
+	}
+)
+
+const (
+	defaultBufferSize = 100
+	defaultTimeout    = 10 * time.Second
+	maxStackDepth     = 64
+)
+
+// Options is a struct which configures a Client.
+type Options struct {
+	// Transport is the Transport to send events.
+	Transport Transport
+
+	// MinSeverity is the minimum severity of Err(s) to be sent.
+	// Because the zero value is SeverityError, Err(s) of which severities are
+	// lower than SeverityError are not sent by default.
+	MinSeverity reasonederror.Severity
+
+	// Release, Environment, ServerName and Tags are set to each event.
+	Release     string
+	Environment string
+	ServerName  string
+	Tags        map[string]string
+
+	// BufferSize is the maximum number of events which wait to be sent.
+	// Events over this are dropped.
+	// If this is zero or less, 100 is used.
+	BufferSize int
+
+	// Timeout is the time limit to send an event.
+	// If this is zero or less, 10 seconds is used.
+	Timeout time.Duration
+
+	// OnDrop is the function which is called when an event is dropped because
+	// the buffer is full or the event could not be sent.
+	OnDrop func()
+
+	// OnError is the function which is called when sending an event fails.
+	OnError func(error)
+}
+
+// Client is a struct which sends events of Err notifications with a
+// Transport.
+// Handle method never blocks on sending, and events are sent by a goroutine.
+type Client struct {
+	opts   Options
+	queue  chan *Event
+	done   chan struct{}
+	closed chan struct{}
+	once   sync.Once
+
+	mutex    sync.Mutex
+	failed   int
+	firstErr error
+}
+
+// NewClient is a function which creates a Client and starts its goroutine.
+func NewClient(opts Options) *Client {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	c := &Client{
+		opts:   opts,
+		queue:  make(chan *Event, opts.BufferSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// Handle method builds an Event of the specified Err with the stack of the
+// current goroutine, and puts it into the buffer.
+// If the severity of the Err is lower than MinSeverity, it is ignored, and if
+// the buffer is full or this Client is closed, it is dropped.
+// This method is used as a synchronous Err notification handler.
+func (c *Client) Handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	if err.Severity() < c.opts.MinSeverity {
+		return
+	}
+
+	select {
+	case <-c.done:
+		c.drop()
+		return
+	default:
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs)
+
+	ev := NewEvent(err, occ, pcs[:n])
+	ev.Release = c.opts.Release
+	ev.Environment = c.opts.Environment
+	ev.ServerName = c.opts.ServerName
+	ev.Tags = c.opts.Tags
+
+	select {
+	case c.queue <- ev:
+	default:
+		c.drop()
+	}
+}
+
+// Close method sends the buffered events and stops this Client.
+// If some events could not be sent, this method returns an Err of which
+// reason is FailToSend.
+func (c *Client) Close() reasonederror.Err {
+	c.once.Do(func() { close(c.done) })
+	<-c.closed
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.failed > 0 {
+		return reasonederror.NewErr(FailToSend{Events: c.failed}, c.firstErr)
+	}
+	return reasonederror.Ok()
+}
+
+func (c *Client) run() {
+	defer close(c.closed)
+
+	for {
+		select {
+		case ev := <-c.queue:
+			c.send(ev)
+		case <-c.done:
+			for {
+				select {
+				case ev := <-c.queue:
+					c.send(ev)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) send(ev *Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+	defer cancel()
+
+	e := c.opts.Transport.Send(ctx, ev)
+	if e == nil {
+		return
+	}
+
+	if c.opts.OnError != nil {
+		c.opts.OnError(e)
+	}
+
+	c.mutex.Lock()
+	c.failed++
+	if c.firstErr == nil {
+		c.firstErr = e
+	}
+	c.mutex.Unlock()
+
+	c.drop()
+}
+
+func (c *Client) drop() {
+	if c.opts.OnDrop != nil {
+		c.opts.OnDrop()
+	}
+}
@@ -0,0 +1,132 @@
+package sentry_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/sentry"
+)
+
+type recorder struct {
+	mutex  sync.Mutex
+	events []*sentry.Event
+}
+
+func (r *recorder) Send(ctx context.Context, ev *sentry.Event) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func TestClient_Handle(t *testing.T) {
+	rec := &recorder{}
+	client := sentry.NewClient(sentry.Options{
+		Transport:   rec,
+		Release:     "app@1.0.0",
+		Environment: "test",
+		ServerName:  "host1",
+		Tags:        map[string]string{"region": "jp"},
+	})
+	handler = client.Handle
+	defer func() { handler = nil }()
+
+	reasonederror.NewErr(CacheMissed{})
+	err := newNestedErr()
+
+	assert.True(t, client.Close().IsOk())
+
+	assert.Equal(t, len(rec.events), 2)
+	ev := rec.events[0]
+	assert.Equal(t, ev.Release, "app@1.0.0")
+	assert.Equal(t, ev.Environment, "test")
+	assert.Equal(t, ev.ServerName, "host1")
+	assert.Equal(t, ev.Tags, map[string]string{"region": "jp"})
+	assert.Equal(t, ev.Level, "error")
+	assert.Equal(t, ev.Fingerprint, []string{err.Cause().(reasonederror.Err).Fingerprint()})
+
+	values := ev.Exception.Values
+	frames := values[len(values)-1].Stacktrace.Frames
+	functions := make([]string, len(frames))
+	for i, f := range frames {
+		functions[i] = f.Function
+	}
+	assert.Contains(t, functions, "newNestedErr")
+	assert.Contains(t, functions, "TestClient_Handle")
+	for _, f := range frames {
+		assert.NotEqual(t, f.Module, "github.com/sttk/reasonederror")
+		assert.NotEqual(t, f.Module, "github.com/sttk/reasonederror/sentry")
+	}
+
+	assert.Equal(t, rec.events[1].Level, "fatal")
+}
+
+func TestClient_minSeverity(t *testing.T) {
+	rec := &recorder{}
+	client := sentry.NewClient(sentry.Options{Transport: rec})
+
+	client.Handle(reasonederror.NewErr(CacheMissed{}), lastOcc)
+	client.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	assert.True(t, client.Close().IsOk())
+
+	assert.Equal(t, len(rec.events), 1)
+	assert.Equal(t, rec.events[0].Exception.Values[0].Type, "FailToStart")
+}
+
+func TestClient_failToSend(t *testing.T) {
+	var dropped int32
+	var errs []error
+	client := sentry.NewClient(sentry.Options{
+		Transport: sentry.TransportFunc(func(ctx context.Context, ev *sentry.Event) error {
+			return errors.New("unreachable")
+		}),
+		OnDrop:  func() { atomic.AddInt32(&dropped, 1) },
+		OnError: func(e error) { errs = append(errs, e) },
+	})
+
+	client.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	client.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+
+	err := client.Close()
+	switch r := err.Reason().(type) {
+	case sentry.FailToSend:
+		assert.Equal(t, r.Events, 2)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause().Error(), "unreachable")
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, atomic.LoadInt32(&dropped), int32(2))
+
+	client.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	assert.Equal(t, atomic.LoadInt32(&dropped), int32(3))
+	assert.True(t, client.Close().IsNotOk())
+}
+
+func TestClient_bufferFull(t *testing.T) {
+	release := make(chan struct{})
+	var sent, dropped int32
+	client := sentry.NewClient(sentry.Options{
+		Transport: sentry.TransportFunc(func(ctx context.Context, ev *sentry.Event) error {
+			<-release
+			atomic.AddInt32(&sent, 1)
+			return nil
+		}),
+		BufferSize: 1,
+		OnDrop:     func() { atomic.AddInt32(&dropped, 1) },
+	})
+
+	for i := 0; i < 5; i++ {
+		client.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	}
+	close(release)
+	assert.True(t, client.Close().IsOk())
+
+	assert.Equal(t, atomic.LoadInt32(&sent)+atomic.LoadInt32(&dropped), int32(5))
+	assert.True(t, atomic.LoadInt32(&dropped) >= 3)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package sentry
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// EnvelopeContentType is the media type of a Sentry envelope.
+const EnvelopeContentType = "application/x-sentry-envelope"
+
+// EncodeEnvelope is a function which encodes the specified Event into an
+// envelope of the Sentry protocol, which consists of the envelope header, the
+// item header and the event payload separated by newlines.
+func EncodeEnvelope(ev *Event, dsn string, sentAt time.Time) ([]byte, error) {
+	payload, e := json.Marshal(ev)
+	if e != nil {
+		return nil, e
+	}
+
+	header, e := json.Marshal(struct {
+		EventID string    `json:"event_id"`
+		SentAt  time.Time `json:"sent_at"`
+		DSN     string    `json:"dsn,omitempty"`
+	}{ev.EventID, sentAt.UTC(), dsn})
+	if e != nil {
+		return nil, e
+	}
+
+	itemHeader, e := json.Marshal(struct {
+		Type   string `json:"type"`
+		Length int    `json:"length"`
+	}{"event", len(payload)})
+	if e != nil {
+		return nil, e
+	}
+
+	var b bytes.Buffer
+	b.Write(header)
+	b.WriteByte('\n')
+	b.Write(itemHeader)
+	b.WriteByte('\n')
+	b.Write(payload)
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
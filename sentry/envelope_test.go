@@ -0,0 +1,43 @@
+package sentry_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/sentry"
+)
+
+func TestEncodeEnvelope(t *testing.T) {
+	ev := sentry.NewEvent(reasonederror.NewErr(FailToStart{}), lastOcc, nil)
+	sentAt := time.Date(2023, 4, 1, 12, 0, 0, 0, time.FixedZone("JST", 9*3600))
+
+	b, e := sentry.EncodeEnvelope(ev, "https://key@example.com/1", sentAt)
+	assert.Nil(t, e)
+
+	lines := bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n"))
+	assert.Equal(t, len(lines), 3)
+
+	var header map[string]interface{}
+	assert.Nil(t, json.Unmarshal(lines[0], &header))
+	assert.Equal(t, header, map[string]interface{}{
+		"event_id": ev.EventID,
+		"sent_at":  "2023-04-01T03:00:00Z",
+		"dsn":      "https://key@example.com/1",
+	})
+
+	var itemHeader map[string]interface{}
+	assert.Nil(t, json.Unmarshal(lines[1], &itemHeader))
+	assert.Equal(t, itemHeader, map[string]interface{}{
+		"type":   "event",
+		"length": float64(len(lines[2])),
+	})
+
+	var payload sentry.Event
+	assert.Nil(t, json.Unmarshal(lines[2], &payload))
+	assert.Equal(t, payload.EventID, ev.EventID)
+	assert.Equal(t, payload.Exception.Values[0].Type, "FailToStart")
+}
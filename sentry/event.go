@@ -0,0 +1,272 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package sentry provides an Err notification handler which sends events to
+// a backend compatible with the Sentry protocol, without depending on the
+// Sentry SDK.
+//
+//	transport, err := sentry.NewHTTPTransport("https://key@sentry.example.com/42")
+//	if err.IsNotOk() {
+//	    return err
+//	}
+//	client := sentry.NewClient(sentry.Options{
+//	    Transport:   transport,
+//	    Release:     "myapp@1.2.3",
+//	    Environment: "production",
+//	})
+//	defer client.Close()
+//
+//	reasonederror.AddSyncErrHandler(client.Handle)
+//	reasonederror.FixErrCfgs()
+//
+// Client.Handle method should be registered as a synchronous handler, because
+// it captures the stack of the goroutine which created an Err.
+package sentry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+// Event is a struct which represents an event of the Sentry protocol.
+type Event struct {
+	EventID     string                     `json:"event_id"`
+	Timestamp   time.Time                  `json:"timestamp"`
+	Level       string                     `json:"level"`
+	Platform    string                     `json:"platform"`
+	Logger      string                     `json:"logger,omitempty"`
+	Release     string                     `json:"release,omitempty"`
+	Environment string                     `json:"environment,omitempty"`
+	ServerName  string                     `json:"server_name,omitempty"`
+	Tags        map[string]string          `json:"tags,omitempty"`
+	Extra       map[string]json.RawMessage `json:"extra,omitempty"`
+	Fingerprint []string                   `json:"fingerprint,omitempty"`
+	Exception   *Exceptions                `json:"exception,omitempty"`
+}
+
+// Exceptions is a struct which holds the exception values of an Event.
+// The values are ordered from the innermost cause to the Err itself.
+type Exceptions struct {
+	Values []Exception `json:"values"`
+}
+
+// Exception is a struct which represents an Err or an error in the cause
+// chain of an Err.
+type Exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value,omitempty"`
+	Module     string      `json:"module,omitempty"`
+	Stacktrace *Stacktrace `json:"stacktrace,omitempty"`
+}
+
+// Stacktrace is a struct which holds stack frames ordered from the oldest
+// call.
+type Stacktrace struct {
+	Frames []Frame `json:"frames"`
+}
+
+// Frame is a struct which represents a stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	Module   string `json:"module,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	AbsPath  string `json:"abs_path,omitempty"`
+	Lineno   int    `json:"lineno,omitempty"`
+	InApp    bool   `json:"in_app"`
+}
+
+const (
+	rootPackage = "github.com/sttk/reasonederror"
+	thisPackage = rootPackage + "/sentry"
+)
+
+// NewEvent is a function which creates an Event of the specified Err and
+// ErrOccasion.
+//
+// The exception values are the Err and the errors in its cause chain, of
+// which types are the reason names, or the type names of errors which are not
+// Err(s), and of which modules are the package paths.
+// The value of an exception is the result of RedactedMessage method of an Err,
+// or the text of an error which is not an Err.
+// An Errs in the cause chain is expanded into the exceptions of its elements
+// and their cause chains, and the texts of Err(s) are never used.
+// The stack trace of the Err is built from the specified program counters,
+// excluding the frames in reasonederror package.
+// The redacted situation of the Err becomes the extra data, and the result of
+// Fingerprint method becomes the fingerprint.
+func NewEvent(err reasonederror.Err, occ reasonederror.ErrOccasion, pcs []uintptr) *Event {
+	ev := &Event{
+		EventID:     newEventID(),
+		Timestamp:   occ.Time().UTC(),
+		Level:       level(err.Severity()),
+		Platform:    "go",
+		Fingerprint: []string{err.Fingerprint()},
+	}
+
+	for k, v := range err.RedactedSituation() {
+		b, e := json.Marshal(v)
+		if e != nil {
+			continue
+		}
+		if ev.Extra == nil {
+			ev.Extra = make(map[string]json.RawMessage)
+		}
+		ev.Extra[k] = b
+	}
+
+	values := exceptions(err)
+	if len(pcs) > 0 {
+		if st := stacktrace(pcs); len(st.Frames) > 0 {
+			values[0].Stacktrace = st
+		}
+	}
+
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+	ev.Exception = &Exceptions{Values: values}
+
+	return ev
+}
+
+func exceptions(err error) []Exception {
+	var values []Exception
+
+	for e := err; e != nil; {
+		switch x := e.(type) {
+		case reasonederror.Err:
+			values = append(values, Exception{
+				Type:   x.ReasonName(),
+				Value:  x.RedactedMessage(),
+				Module: x.ReasonPackage(),
+			})
+			e = x.Cause()
+
+		case reasonederror.Errs:
+			for _, y := range x {
+				values = append(values, exceptions(y)...)
+			}
+			return values
+
+		default:
+			// The text of an error which wraps an Err contains the text of
+			// the Err, so it is skipped and the wrapped error is followed.
+			if wrapsErr(e) {
+				e = errors.Unwrap(e)
+				continue
+			}
+			t := reflect.TypeOf(e)
+			pkg := t.PkgPath()
+			if t.Kind() == reflect.Ptr {
+				pkg = t.Elem().PkgPath()
+			}
+			return append(values, Exception{
+				Type:   t.String(),
+				Value:  e.Error(),
+				Module: pkg,
+			})
+		}
+	}
+
+	return values
+}
+
+func wrapsErr(err error) bool {
+	var e reasonederror.Err
+	if errors.As(err, &e) {
+		return true
+	}
+	var errs reasonederror.Errs
+	return errors.As(err, &errs)
+}
+
+func stacktrace(pcs []uintptr) *Stacktrace {
+	var frames []Frame
+
+	it := runtime.CallersFrames(pcs)
+	for {
+		f, more := it.Next()
+		module, function := splitFunction(f.Function)
+		if !isExcluded(module) {
+			frames = append(frames, Frame{
+				Function: function,
+				Module:   module,
+				Filename: filepathBase(f.File),
+				AbsPath:  f.File,
+				Lineno:   f.Line,
+				InApp:    !isStdPackage(module),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return &Stacktrace{Frames: frames}
+}
+
+// isExcluded reports whether frames of a package are excluded from a stack
+// trace, which are of the runtime, reasonederror and this package.
+func isExcluded(pkg string) bool {
+	return pkg == "runtime" || strings.HasPrefix(pkg, "runtime/") ||
+		pkg == rootPackage || pkg == thisPackage
+}
+
+// splitFunction splits a function name like "github.com/a/b.(*T).Method"
+// into the package path and the rest.
+func splitFunction(name string) (string, string) {
+	slash := strings.LastIndexByte(name, '/')
+	dot := strings.IndexByte(name[slash+1:], '.')
+	if dot < 0 {
+		return "", name
+	}
+	i := slash + 1 + dot
+	return name[:i], name[i+1:]
+}
+
+func filepathBase(path string) string {
+	return path[strings.LastIndexByte(path, '/')+1:]
+}
+
+// isStdPackage reports whether a package is in the standard library, of which
+// first path element has no dot.
+func isStdPackage(pkg string) bool {
+	first := pkg
+	if i := strings.IndexByte(pkg, '/'); i >= 0 {
+		first = pkg[:i]
+	}
+	return !strings.Contains(first, ".")
+}
+
+func level(s reasonederror.Severity) string {
+	switch {
+	case s <= reasonederror.SeverityDebug:
+		return "debug"
+	case s == reasonederror.SeverityInfo:
+		return "info"
+	case s == reasonederror.SeverityWarn:
+		return "warning"
+	case s == reasonederror.SeverityError:
+		return "error"
+	default:
+		return "fatal"
+	}
+}
+
+func newEventID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,164 @@
+package sentry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/sentry"
+)
+
+type /* error reasons */ (
+	FailToLoadConfig struct {
+		Path     string
+		Password string `redact:"true"`
+	}
+	FailToStart     struct{}
+	CacheMissed     struct{}
+	FailToConnectDB struct {
+		DSN string `redact:"true"`
+	}
+)
+
+func (r FailToStart) Message() string {
+	return "the server could not start"
+}
+
+func (r FailToStart) Severity() reasonederror.Severity {
+	return reasonederror.SeverityFatal
+}
+
+func (r CacheMissed) Severity() reasonederror.Severity {
+	return reasonederror.SeverityInfo
+}
+
+var (
+	lastOcc reasonederror.ErrOccasion
+	handler func(reasonederror.Err, reasonederror.ErrOccasion)
+)
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastOcc = occ
+		if handler != nil {
+			handler(err, occ)
+		}
+	})
+	reasonederror.SetReasonMessage(FailToConnectDB{}, "could not connect to {{.DSN}}")
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+func newNestedErr() reasonederror.Err {
+	cause := &fs.PathError{Op: "open", Path: "/etc/app.conf", Err: fs.ErrNotExist}
+	err := reasonederror.NewErr(FailToLoadConfig{Path: "/etc/app.conf", Password: "pw"}, cause)
+	return reasonederror.NewErr(FailToStart{}, err)
+}
+
+func TestNewEvent(t *testing.T) {
+	err := newNestedErr()
+	occ := lastOcc
+
+	ev := sentry.NewEvent(err, occ, nil)
+
+	assert.Equal(t, len(ev.EventID), 32)
+	assert.Equal(t, ev.Timestamp, occ.Time().UTC())
+	assert.Equal(t, ev.Level, "fatal")
+	assert.Equal(t, ev.Platform, "go")
+	assert.Equal(t, ev.Fingerprint, []string{err.Fingerprint()})
+	assert.Equal(t, ev.Extra, map[string]json.RawMessage{
+		"Path":     json.RawMessage(`"/etc/app.conf"`),
+		"Password": json.RawMessage(`"[REDACTED]"`),
+	})
+	assert.Equal(t, ev.Exception.Values, []sentry.Exception{
+		{Type: "*fs.PathError", Value: "open /etc/app.conf: file does not exist", Module: "io/fs"},
+		{Type: "FailToLoadConfig", Module: "github.com/sttk/reasonederror/sentry_test"},
+		{Type: "FailToStart", Value: "the server could not start", Module: "github.com/sttk/reasonederror/sentry_test"},
+	})
+
+	assert.NotEqual(t, sentry.NewEvent(err, occ, nil).EventID, ev.EventID)
+}
+
+func TestNewEvent_redactedMessageAndErrs(t *testing.T) {
+	errs := reasonederror.Errs{
+		reasonederror.NewErr(FailToConnectDB{DSN: "postgres://u:secret@db"}),
+		reasonederror.NewErr(FailToLoadConfig{Path: "/a", Password: "secret"}, fs.ErrNotExist),
+	}
+	err := reasonederror.NewErr(FailToStart{}, fmt.Errorf("tasks: %w", errs))
+
+	ev := sentry.NewEvent(err, lastOcc, nil)
+	assert.Equal(t, ev.Exception.Values, []sentry.Exception{
+		{Type: "*errors.errorString", Value: "file does not exist", Module: "errors"},
+		{Type: "FailToLoadConfig", Module: "github.com/sttk/reasonederror/sentry_test"},
+		{Type: "FailToConnectDB", Value: "could not connect to [REDACTED]", Module: "github.com/sttk/reasonederror/sentry_test"},
+		{Type: "FailToStart", Value: "the server could not start", Module: "github.com/sttk/reasonederror/sentry_test"},
+	})
+
+	b, e := json.Marshal(ev)
+	assert.Nil(t, e)
+	assert.NotContains(t, string(b), "secret")
+}
+
+func TestNewEvent_level(t *testing.T) {
+	tests := []struct {
+		severity reasonederror.Severity
+		level    string
+	}{
+		{reasonederror.SeverityDebug, "debug"},
+		{reasonederror.SeverityInfo, "info"},
+		{reasonederror.SeverityWarn, "warning"},
+		{reasonederror.SeverityError, "error"},
+		{reasonederror.SeverityFatal, "fatal"},
+	}
+	for _, test := range tests {
+		ev := sentry.NewEvent(reasonederror.NewErr(severityReason{test.severity}), lastOcc, nil)
+		assert.Equal(t, ev.Level, test.level)
+	}
+}
+
+type severityReason struct {
+	s reasonederror.Severity
+}
+
+func (r severityReason) Severity() reasonederror.Severity {
+	return r.s
+}
+
+func captureStack() []uintptr {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(1, pcs)
+	return pcs[:n]
+}
+
+func TestNewEvent_stacktrace(t *testing.T) {
+	err := reasonederror.NewErr(CacheMissed{})
+	pcs := captureStack()
+
+	ev := sentry.NewEvent(err, lastOcc, pcs)
+	values := ev.Exception.Values
+	st := values[len(values)-1].Stacktrace
+
+	frames := st.Frames
+	last := frames[len(frames)-1]
+	assert.Equal(t, last.Function, "captureStack")
+	assert.Equal(t, last.Module, "github.com/sttk/reasonederror/sentry_test")
+	assert.Equal(t, last.Filename, "event_test.go")
+	assert.True(t, last.InApp)
+	assert.True(t, last.Lineno > 0)
+
+	caller := frames[len(frames)-2]
+	assert.Equal(t, caller.Function, "TestNewEvent_stacktrace")
+
+	for _, f := range frames {
+		assert.NotEqual(t, f.Module, "runtime")
+		if f.Module == "testing" {
+			assert.False(t, f.InApp)
+		}
+	}
+}
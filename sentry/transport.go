@@ -0,0 +1,116 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// InvalidDSN is an error reason which indicates that a DSN is malformed.
+	InvalidDSN struct {
+		DSN string `redact:"true"`
+	}
+)
+
+// Transport is an interface which sends an Event to a backend.
+type Transport interface {
+	Send(ctx context.Context, ev *Event) error
+}
+
+// TransportFunc is a function type which implements Transport.
+type TransportFunc func(ctx context.Context, ev *Event) error
+
+// Send method calls this function.
+func (f TransportFunc) Send(ctx context.Context, ev *Event) error {
+	return f(ctx, ev)
+}
+
+const clientName = "reasonederror-sentry/1.0"
+
+// HTTPTransport is a Transport which posts envelopes to the envelope endpoint
+// of a backend specified by a DSN.
+type HTTPTransport struct {
+	dsn      string
+	endpoint string
+	auth     string
+
+	// Client is the HTTP client to send requests.
+	// If this is nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+// NewHTTPTransport is a function which creates an HTTPTransport for the
+// specified DSN like "https://<public key>@<host>/<project id>".
+// If the DSN is malformed, this function returns an Err of which reason is
+// InvalidDSN.
+func NewHTTPTransport(dsn string) (*HTTPTransport, reasonederror.Err) {
+	u, e := url.Parse(dsn)
+	if e != nil {
+		return nil, reasonederror.NewErr(InvalidDSN{DSN: dsn}, e)
+	}
+
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndexByte(path, '/')
+	prefix, projectID := "", path
+	if i >= 0 {
+		prefix, projectID = "/"+path[:i], path[i+1:]
+	}
+
+	key := u.User.Username()
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		key == "" || projectID == "" {
+		return nil, reasonederror.NewErr(InvalidDSN{DSN: dsn})
+	}
+
+	return &HTTPTransport{
+		dsn:      dsn,
+		endpoint: u.Scheme + "://" + u.Host + prefix + "/api/" + projectID + "/envelope/",
+		auth:     "Sentry sentry_version=7, sentry_client=" + clientName + ", sentry_key=" + key,
+	}, reasonederror.Ok()
+}
+
+// Send method posts the specified Event as an envelope.
+// If the backend responds with a status other than 2xx, this method returns
+// an error.
+func (t *HTTPTransport) Send(ctx context.Context, ev *Event) error {
+	body, e := EncodeEnvelope(ev, t.dsn, time.Now())
+	if e != nil {
+		return e
+	}
+
+	req, e := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if e != nil {
+		return e
+	}
+	req.Header.Set("Content-Type", EnvelopeContentType)
+	req.Header.Set("X-Sentry-Auth", t.auth)
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, e := client.Do(req)
+	if e != nil {
+		return e
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sentry responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
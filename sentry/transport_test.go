@@ -0,0 +1,75 @@
+package sentry_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/sentry"
+)
+
+func TestNewHTTPTransport_invalidDSN(t *testing.T) {
+	dsns := []string{
+		"",
+		"://bad",
+		"ftp://key@example.com/1",
+		"https://example.com/1",
+		"https://key@example.com/",
+		"https://key@/1",
+	}
+	for _, dsn := range dsns {
+		tr, err := sentry.NewHTTPTransport(dsn)
+		assert.Nil(t, tr, dsn)
+		switch r := err.Reason().(type) {
+		case sentry.InvalidDSN:
+			assert.Equal(t, r.DSN, dsn)
+		default:
+			assert.Fail(t, err.Error())
+		}
+	}
+}
+
+func TestHTTPTransport_Send(t *testing.T) {
+	var path, auth, contentType string
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		auth = r.Header.Get("X-Sentry-Auth")
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/prefix/42"
+	tr, err := sentry.NewHTTPTransport(dsn)
+	assert.True(t, err.IsOk())
+
+	ev := sentry.NewEvent(reasonederror.NewErr(FailToStart{}), lastOcc, nil)
+	assert.Nil(t, tr.Send(context.Background(), ev))
+
+	assert.Equal(t, path, "/prefix/api/42/envelope/")
+	assert.Equal(t, auth, "Sentry sentry_version=7, sentry_client=reasonederror-sentry/1.0, sentry_key=pubkey")
+	assert.Equal(t, contentType, sentry.EnvelopeContentType)
+	assert.True(t, bytes.Contains(body, []byte(`"event_id":"`+ev.EventID+`"`)))
+	assert.Equal(t, bytes.Count(body, []byte("\n")), 3)
+}
+
+func TestHTTPTransport_Send_errorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	tr, _ := sentry.NewHTTPTransport(strings.Replace(srv.URL, "http://", "http://k@", 1) + "/1")
+	tr.Client = srv.Client()
+
+	ev := sentry.NewEvent(reasonederror.NewErr(FailToStart{}), lastOcc, nil)
+	e := tr.Send(context.Background(), ev)
+	assert.Equal(t, e.Error(), "sentry responded with status 429")
+}
@@ -0,0 +1,162 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package reasonederror
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// Severity is a type which represents how bad an error is.
+// The zero value is SeverityError, which is the severity of a reason that
+// declares nothing.
+type Severity int
+
+const (
+	SeverityDebug Severity = iota - 3
+	SeverityInfo
+	SeverityWarn
+	SeverityError
+	SeverityFatal
+)
+
+// String method returns the name of this Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarn:
+		return "warn"
+	case SeverityError:
+		return "error"
+	case SeverityFatal:
+		return "fatal"
+	default:
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// Class is a type which represents classification flags of an error.
+// Flags can be combined with bitwise OR.
+type Class uint
+
+const (
+	// ClassUserError indicates that an error is caused by a user, like an
+	// invalid input. An error without this flag is regarded as a system error.
+	ClassUserError Class = 1 << iota
+
+	// ClassTransient indicates that an error is caused by a temporary
+	// condition and may not occur again.
+	ClassTransient
+
+	// ClassSecurity indicates that an error is relevant to security, like an
+	// authentication failure.
+	ClassSecurity
+
+	// ClassRetryable indicates that an operation which caused an error is
+	// worth retrying.
+	ClassRetryable
+)
+
+// ReasonAttrs is a struct which contains the severity and the classification
+// flags of a reason type.
+type ReasonAttrs struct {
+	Severity Severity
+	Class    Class
+}
+
+// severer is an interface for reason structs which declare their severities
+// by themselves.
+type severer interface {
+	Severity() Severity
+}
+
+// classifier is an interface for reason structs which declare their
+// classification flags by themselves.
+type classifier interface {
+	Class() Class
+}
+
+var reasonAttrs = make(map[reflect.Type]ReasonAttrs)
+
+// SetReasonAttrs is a function which registers the severity and the
+// classification flags for the type of the specified reason.
+// If a reason struct has Severity or Class method, its result takes
+// precedence over the registered attributes.
+// This function is effective only before calling FixErrCfgs function.
+func SetReasonAttrs(reason interface{}, attrs ReasonAttrs) {
+	t := reasonType(reason)
+	if t == nil {
+		return
+	}
+
+	errCfgMutex.Lock()
+	defer errCfgMutex.Unlock()
+
+	if isErrCfgsFixed {
+		return
+	}
+
+	reasonAttrs[t] = attrs
+}
+
+// Severity method returns the severity of this Err.
+// If the reason declares nothing, this method returns SeverityError.
+func (err Err) Severity() Severity {
+	if s, ok := err.reason.(severer); ok {
+		return s.Severity()
+	}
+	return reasonAttrs[reasonType(err.reason)].Severity
+}
+
+// Class method returns the classification flags of this Err.
+func (err Err) Class() Class {
+	if c, ok := err.reason.(classifier); ok {
+		return c.Class()
+	}
+	return reasonAttrs[reasonType(err.reason)].Class
+}
+
+// IsUserError method checks whether this Err is caused by a user.
+func (err Err) IsUserError() bool {
+	return err.IsNotOk() && (err.Class()&ClassUserError) != 0
+}
+
+// IsSystemError method checks whether this Err is not caused by a user.
+func (err Err) IsSystemError() bool {
+	return err.IsNotOk() && (err.Class()&ClassUserError) == 0
+}
+
+// IsTransient method checks whether this Err is caused by a temporary
+// condition.
+func (err Err) IsTransient() bool {
+	return err.IsNotOk() && (err.Class()&ClassTransient) != 0
+}
+
+// IsSecurityRelevant method checks whether this Err is relevant to security.
+func (err Err) IsSecurityRelevant() bool {
+	return err.IsNotOk() && (err.Class()&ClassSecurity) != 0
+}
+
+// IsRetryable method checks whether an operation which caused this Err is
+// worth retrying.
+func (err Err) IsRetryable() bool {
+	return err.IsNotOk() && (err.Class()&ClassRetryable) != 0
+}
+
+// FilterBySeverity is a function which wraps an Err creation event handler
+// so that it is executed only for Err(s) of which severities are equal to or
+// higher than the specified severity.
+func FilterBySeverity(
+	min Severity, handler func(Err, ErrOccasion),
+) func(Err, ErrOccasion) {
+	return func(err Err, occ ErrOccasion) {
+		if err.Severity() >= min {
+			handler(err, occ)
+		}
+	}
+}
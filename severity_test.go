@@ -0,0 +1,150 @@
+package reasonederror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type /* error reasons */ (
+	ReasonWithNoAttrs       struct{}
+	ReasonWithAttrs         struct{}
+	ReasonDeclaringAttrs    struct{}
+	ReasonDeclaringSeverity struct{}
+)
+
+func (r ReasonDeclaringAttrs) Severity() Severity {
+	return SeverityFatal
+}
+
+func (r ReasonDeclaringAttrs) Class() Class {
+	return ClassTransient | ClassSecurity
+}
+
+func (r *ReasonDeclaringSeverity) Severity() Severity {
+	return SeverityDebug
+}
+
+func TestSeverity_String(t *testing.T) {
+	assert.Equal(t, SeverityDebug.String(), "debug")
+	assert.Equal(t, SeverityInfo.String(), "info")
+	assert.Equal(t, SeverityWarn.String(), "warn")
+	assert.Equal(t, SeverityError.String(), "error")
+	assert.Equal(t, SeverityFatal.String(), "fatal")
+	assert.Equal(t, Severity(9).String(), "Severity(9)")
+
+	assert.True(t, SeverityDebug < SeverityInfo)
+	assert.True(t, SeverityInfo < SeverityWarn)
+	assert.True(t, SeverityWarn < SeverityError)
+	assert.True(t, SeverityError < SeverityFatal)
+	assert.Equal(t, Severity(0), SeverityError)
+}
+
+func TestErr_Severity_default(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	err := NewErr(ReasonWithNoAttrs{})
+	assert.Equal(t, err.Severity(), SeverityError)
+	assert.Equal(t, err.Class(), Class(0))
+	assert.False(t, err.IsUserError())
+	assert.True(t, err.IsSystemError())
+	assert.False(t, err.IsTransient())
+	assert.False(t, err.IsSecurityRelevant())
+}
+
+func TestErr_Severity_ok(t *testing.T) {
+	err := Ok()
+	assert.Equal(t, err.Severity(), SeverityError)
+	assert.Equal(t, err.Class(), Class(0))
+	assert.False(t, err.IsUserError())
+	assert.False(t, err.IsSystemError())
+	assert.False(t, err.IsTransient())
+	assert.False(t, err.IsSecurityRelevant())
+}
+
+func TestSetReasonAttrs(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	SetReasonAttrs(ReasonWithAttrs{}, ReasonAttrs{
+		Severity: SeverityWarn,
+		Class:    ClassUserError,
+	})
+
+	err := NewErr(ReasonWithAttrs{})
+	assert.Equal(t, err.Severity(), SeverityWarn)
+	assert.Equal(t, err.Class(), ClassUserError)
+	assert.True(t, err.IsUserError())
+	assert.False(t, err.IsSystemError())
+	assert.False(t, err.IsTransient())
+	assert.False(t, err.IsSecurityRelevant())
+
+	err = NewErr(&ReasonWithAttrs{})
+	assert.Equal(t, err.Severity(), SeverityWarn)
+	assert.True(t, err.IsUserError())
+}
+
+func TestSetReasonAttrs_afterFixErrCfgs(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	FixErrCfgs()
+
+	SetReasonAttrs(ReasonWithAttrs{}, ReasonAttrs{Severity: SeverityWarn})
+
+	err := NewErr(ReasonWithAttrs{})
+	assert.Equal(t, err.Severity(), SeverityError)
+}
+
+func TestSetReasonAttrs_nilReason(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	SetReasonAttrs(nil, ReasonAttrs{Severity: SeverityWarn})
+	assert.Equal(t, len(reasonAttrs), 0)
+}
+
+func TestErr_Severity_declaredByReason(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	SetReasonAttrs(ReasonDeclaringAttrs{}, ReasonAttrs{
+		Severity: SeverityInfo,
+		Class:    ClassUserError,
+	})
+
+	err := NewErr(ReasonDeclaringAttrs{})
+	assert.Equal(t, err.Severity(), SeverityFatal)
+	assert.Equal(t, err.Class(), ClassTransient|ClassSecurity)
+	assert.False(t, err.IsUserError())
+	assert.True(t, err.IsSystemError())
+	assert.True(t, err.IsTransient())
+	assert.True(t, err.IsSecurityRelevant())
+
+	err = NewErr(&ReasonDeclaringSeverity{})
+	assert.Equal(t, err.Severity(), SeverityDebug)
+
+	err = NewErr(ReasonDeclaringSeverity{})
+	assert.Equal(t, err.Severity(), SeverityError)
+}
+
+func TestFilterBySeverity(t *testing.T) {
+	ClearErrHandlers()
+	defer ClearErrHandlers()
+
+	SetReasonAttrs(ReasonWithAttrs{}, ReasonAttrs{Severity: SeverityWarn})
+
+	var names []string
+	AddSyncErrHandler(FilterBySeverity(SeverityError, func(err Err, occ ErrOccasion) {
+		names = append(names, err.ReasonName())
+	}))
+	FixErrCfgs()
+
+	NewErr(ReasonWithAttrs{})
+	NewErr(ReasonWithNoAttrs{})
+	NewErr(ReasonDeclaringAttrs{})
+	NewErr(&ReasonDeclaringSeverity{})
+
+	assert.Equal(t, names, []string{"ReasonWithNoAttrs", "ReasonDeclaringAttrs"})
+}
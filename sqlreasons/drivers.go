@@ -0,0 +1,214 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package sqlreasons
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// sqlStater is an interface for errors of PostgreSQL drivers, like
+// *pgconn.PgError of pgx and *pq.Error of lib/pq, which provide SQLSTATE
+// codes.
+type sqlStater interface {
+	SQLState() string
+}
+
+// PostgresMapper is a Mapper for errors of PostgreSQL drivers which have
+// SQLState method, like pgx and lib/pq.
+// The constraint name is read from the field named ConstraintName or
+// Constraint of the error if it exists.
+func PostgresMapper(err error) (interface{}, bool) {
+	var e sqlStater
+	if !errors.As(err, &e) {
+		return nil, false
+	}
+
+	code := e.SQLState()
+	constraint := stringField(e, "ConstraintName", "Constraint")
+
+	switch {
+	case code == "23505":
+		return UniqueViolation{Constraint: constraint}, true
+	case code == "23503":
+		return ForeignKeyViolation{Constraint: constraint}, true
+	case code == "23502":
+		return NotNullViolation{Column: stringField(e, "ColumnName", "Column")}, true
+	case code == "23514":
+		return CheckViolation{Constraint: constraint}, true
+	case code == "40001":
+		return SerializationFailure{}, true
+	case code == "40P01":
+		return Deadlock{}, true
+	case code == "55P03":
+		return LockTimeout{}, true
+	case strings.HasPrefix(code, "08"), code == "57P01":
+		return ConnectionLost{}, true
+	default:
+		return nil, false
+	}
+}
+
+// MySQLMapper is a Mapper for errors of MySQL drivers which have an unsigned
+// integer field named Number holding a MySQL error number, like
+// *mysql.MySQLError of go-sql-driver/mysql.
+func MySQLMapper(err error) (interface{}, bool) {
+	e, number, ok := findUintField(err, "Number")
+	if !ok {
+		return nil, false
+	}
+
+	msg := e.Error()
+
+	switch number {
+	case 1062, 1586:
+		return UniqueViolation{Constraint: quotedAfter(msg, "for key ")}, true
+	case 1216, 1217, 1451, 1452:
+		return ForeignKeyViolation{Constraint: quotedAfter(msg, "CONSTRAINT ")}, true
+	case 1048, 1364:
+		return NotNullViolation{Column: quotedAfter(msg, "Column ")}, true
+	case 3819:
+		return CheckViolation{Constraint: quotedAfter(msg, "Check constraint ")}, true
+	case 1213:
+		return Deadlock{}, true
+	case 1205:
+		return LockTimeout{}, true
+	case 2006, 2013:
+		return ConnectionLost{}, true
+	default:
+		return nil, false
+	}
+}
+
+// sqliteCoder is an interface for errors of SQLite drivers, like *sqlite.Error
+// of modernc.org/sqlite, which provide extended result codes.
+type sqliteCoder interface {
+	Code() int
+}
+
+// SQLiteMapper is a Mapper for errors of SQLite drivers which have Code method
+// returning an extended result code, like modernc.org/sqlite, or an integer
+// field named ExtendedCode, like mattn/go-sqlite3.
+// The constraint name is read from the error message like
+// "UNIQUE constraint failed: users.email".
+func SQLiteMapper(err error) (interface{}, bool) {
+	var code int
+
+	var c sqliteCoder
+	if errors.As(err, &c) {
+		code = c.Code()
+	} else if _, n, ok := findIntField(err, "ExtendedCode"); ok {
+		code = n
+	} else {
+		return nil, false
+	}
+
+	constraint := after(err.Error(), "constraint failed: ")
+
+	switch code {
+	case 2067, 1555: // SQLITE_CONSTRAINT_UNIQUE, SQLITE_CONSTRAINT_PRIMARYKEY
+		return UniqueViolation{Constraint: constraint}, true
+	case 787: // SQLITE_CONSTRAINT_FOREIGNKEY
+		return ForeignKeyViolation{Constraint: constraint}, true
+	case 1299: // SQLITE_CONSTRAINT_NOTNULL
+		return NotNullViolation{Column: constraint}, true
+	case 275: // SQLITE_CONSTRAINT_CHECK
+		return CheckViolation{Constraint: constraint}, true
+	}
+
+	switch code & 0xff {
+	case 5, 6: // SQLITE_BUSY, SQLITE_LOCKED
+		return LockTimeout{}, true
+	default:
+		return nil, false
+	}
+}
+
+func stringField(v interface{}, names ...string) string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range names {
+		f := rv.FieldByName(name)
+		if f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
+
+func findUintField(err error, name string) (error, uint64, bool) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		rv := reflect.Indirect(reflect.ValueOf(e))
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		f := rv.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return e, f.Uint(), true
+		}
+	}
+	return nil, 0, false
+}
+
+func findIntField(err error, name string) (error, int, bool) {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		rv := reflect.Indirect(reflect.ValueOf(e))
+		if rv.Kind() != reflect.Struct {
+			continue
+		}
+		f := rv.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return e, int(f.Int()), true
+		}
+	}
+	return nil, 0, false
+}
+
+// quotedAfter returns the first quoted text after the specified prefix in a
+// message, like "users.email" in "... for key 'users.email'".
+func quotedAfter(msg, prefix string) string {
+	i := strings.Index(msg, prefix)
+	if i < 0 {
+		return ""
+	}
+	s := msg[i+len(prefix):]
+	if len(s) == 0 {
+		return ""
+	}
+	q := s[0]
+	if q != '\'' && q != '`' && q != '"' {
+		return ""
+	}
+	j := strings.IndexByte(s[1:], q)
+	if j < 0 {
+		return ""
+	}
+	return s[1 : j+1]
+}
+
+// after returns the text after the last occurrence of the specified prefix
+// in a message, without a trailing result code like " (2067)".
+func after(msg, prefix string) string {
+	i := strings.LastIndex(msg, prefix)
+	if i < 0 {
+		return ""
+	}
+	s := msg[i+len(prefix):]
+	if j := strings.LastIndex(s, " ("); j >= 0 && strings.HasSuffix(s, ")") {
+		s = s[:j]
+	}
+	return s
+}
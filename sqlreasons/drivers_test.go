@@ -0,0 +1,138 @@
+package sqlreasons_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror/sqlreasons"
+)
+
+type fakePgError struct {
+	Code           string
+	ConstraintName string
+	ColumnName     string
+}
+
+func (e *fakePgError) Error() string    { return "pg error " + e.Code }
+func (e *fakePgError) SQLState() string { return e.Code }
+
+type fakeMySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *fakeMySQLError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+type fakeSQLite3Error struct {
+	Code         int
+	ExtendedCode int
+	Message      string
+}
+
+func (e fakeSQLite3Error) Error() string { return e.Message }
+
+type fakeSQLiteError struct {
+	code int
+	msg  string
+}
+
+func (e *fakeSQLiteError) Error() string { return e.msg }
+func (e *fakeSQLiteError) Code() int     { return e.code }
+
+func TestPostgresMapper(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected interface{}
+	}{
+		{&fakePgError{Code: "23505", ConstraintName: "users_email_key"},
+			sqlreasons.UniqueViolation{Constraint: "users_email_key"}},
+		{&fakePgError{Code: "23503", ConstraintName: "orders_user_fk"},
+			sqlreasons.ForeignKeyViolation{Constraint: "orders_user_fk"}},
+		{&fakePgError{Code: "23502", ColumnName: "name"},
+			sqlreasons.NotNullViolation{Column: "name"}},
+		{&fakePgError{Code: "23514", ConstraintName: "age_check"},
+			sqlreasons.CheckViolation{Constraint: "age_check"}},
+		{&fakePgError{Code: "40001"}, sqlreasons.SerializationFailure{}},
+		{&fakePgError{Code: "40P01"}, sqlreasons.Deadlock{}},
+		{&fakePgError{Code: "55P03"}, sqlreasons.LockTimeout{}},
+		{&fakePgError{Code: "08006"}, sqlreasons.ConnectionLost{}},
+		{&fakePgError{Code: "57P01"}, sqlreasons.ConnectionLost{}},
+		{fmt.Errorf("exec: %w", &fakePgError{Code: "40P01"}), sqlreasons.Deadlock{}},
+	}
+	for _, test := range tests {
+		reason, ok := sqlreasons.PostgresMapper(test.err)
+		assert.True(t, ok, test.err.Error())
+		assert.Equal(t, reason, test.expected, test.err.Error())
+	}
+
+	_, ok := sqlreasons.PostgresMapper(&fakePgError{Code: "42601"})
+	assert.False(t, ok)
+	_, ok = sqlreasons.PostgresMapper(&fakeMySQLError{Number: 1062})
+	assert.False(t, ok)
+}
+
+func TestMySQLMapper(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected interface{}
+	}{
+		{&fakeMySQLError{1062, "Duplicate entry 'a@b.c' for key 'users.email'"},
+			sqlreasons.UniqueViolation{Constraint: "users.email"}},
+		{&fakeMySQLError{1452, "Cannot add or update a child row: a foreign key constraint fails (`db`.`orders`, CONSTRAINT `orders_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			sqlreasons.ForeignKeyViolation{Constraint: "orders_user_fk"}},
+		{&fakeMySQLError{1048, "Column 'name' cannot be null"},
+			sqlreasons.NotNullViolation{Column: "name"}},
+		{&fakeMySQLError{3819, "Check constraint 'age_check' is violated."},
+			sqlreasons.CheckViolation{Constraint: "age_check"}},
+		{&fakeMySQLError{1213, "Deadlock found"}, sqlreasons.Deadlock{}},
+		{&fakeMySQLError{1205, "Lock wait timeout exceeded"}, sqlreasons.LockTimeout{}},
+		{&fakeMySQLError{2006, "MySQL server has gone away"}, sqlreasons.ConnectionLost{}},
+		{fmt.Errorf("exec: %w", &fakeMySQLError{1213, "Deadlock found"}), sqlreasons.Deadlock{}},
+	}
+	for _, test := range tests {
+		reason, ok := sqlreasons.MySQLMapper(test.err)
+		assert.True(t, ok, test.err.Error())
+		assert.Equal(t, reason, test.expected, test.err.Error())
+	}
+
+	_, ok := sqlreasons.MySQLMapper(&fakeMySQLError{1064, "syntax error"})
+	assert.False(t, ok)
+	_, ok = sqlreasons.MySQLMapper(&fakePgError{Code: "23505"})
+	assert.False(t, ok)
+}
+
+func TestSQLiteMapper(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected interface{}
+	}{
+		{fakeSQLite3Error{19, 2067, "UNIQUE constraint failed: users.email"},
+			sqlreasons.UniqueViolation{Constraint: "users.email"}},
+		{fakeSQLite3Error{19, 1555, "UNIQUE constraint failed: users.id"},
+			sqlreasons.UniqueViolation{Constraint: "users.id"}},
+		{fakeSQLite3Error{19, 787, "FOREIGN KEY constraint failed"},
+			sqlreasons.ForeignKeyViolation{}},
+		{fakeSQLite3Error{19, 1299, "NOT NULL constraint failed: users.name"},
+			sqlreasons.NotNullViolation{Column: "users.name"}},
+		{fakeSQLite3Error{19, 275, "CHECK constraint failed: age_check"},
+			sqlreasons.CheckViolation{Constraint: "age_check"}},
+		{fakeSQLite3Error{5, 5, "database is locked"}, sqlreasons.LockTimeout{}},
+		{&fakeSQLiteError{2067, "constraint failed: UNIQUE constraint failed: users.email (2067)"},
+			sqlreasons.UniqueViolation{Constraint: "users.email"}},
+		{&fakeSQLiteError{517, "database is locked (517)"}, sqlreasons.LockTimeout{}},
+		{&fakeSQLiteError{6, "database table is locked"}, sqlreasons.LockTimeout{}},
+	}
+	for _, test := range tests {
+		reason, ok := sqlreasons.SQLiteMapper(test.err)
+		assert.True(t, ok, test.err.Error())
+		assert.Equal(t, reason, test.expected, test.err.Error())
+	}
+
+	_, ok := sqlreasons.SQLiteMapper(fakeSQLite3Error{1, 1, "near \"SELEC\": syntax error"})
+	assert.False(t, ok)
+	_, ok = sqlreasons.SQLiteMapper(&fakePgError{Code: "23505"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package sqlreasons
+
+import (
+	"strings"
+)
+
+// RedactQuery is a function which replaces string and numeric literals in the
+// specified SQL query with "?", so that the query can be recorded without
+// values which may be sensitive.
+// Placeholders like "$1" and "?" and identifiers like "col1" are kept.
+//
+// The following literals are replaced:
+//
+//   - string literals in single quotes, in which a doubled quote is an
+//     escaped quote, like 'O”Brien',
+//   - string literals with E, B, N or X prefix, like X'DEADBEEF',
+//   - string literals in double quotes, which are string literals in MySQL.
+//     Therefore quoted identifiers of standard SQL are also replaced,
+//   - dollar-quoted strings of PostgreSQL, like $$text$$ and $tag$text$tag$,
+//   - numeric literals including hexadecimal and binary ones, like 0xDEADBEEF
+//     and 0b1010, and ones with exponents, like 1.5e-3.
+//
+// A backslash in a quoted literal escapes the next character in MySQL by
+// default and in PostgreSQL strings with E prefix, like 'O\'Brien', but is a
+// usual character in standard strings of PostgreSQL, like 'C:\'.
+// Because the dialect is unknown, this function parses the query in both ways
+// and replaces the parts which are literals in either way, so that no value
+// is left in the result, though more parts than needed may be replaced.
+// Consecutive replaced parts are replaced with one "?".
+//
+// An unterminated literal is replaced to the end of the query.
+func RedactQuery(query string) string {
+	escaped := literalMask(query, true)
+	standard := literalMask(query, false)
+
+	var b strings.Builder
+	b.Grow(len(query))
+
+	n := len(query)
+	for i := 0; i < n; i++ {
+		if escaped[i] || standard[i] {
+			if i == 0 || !(escaped[i-1] || standard[i-1]) {
+				b.WriteByte('?')
+			}
+			continue
+		}
+		b.WriteByte(query[i])
+	}
+
+	return b.String()
+}
+
+// literalMask returns a slice of which elements are true at the indexes of the
+// literals in the specified query.
+// If backslash is true, a backslash in quoted literals escapes the next
+// character, otherwise only in strings with E prefix.
+func literalMask(query string, backslash bool) []bool {
+	n := len(query)
+	mask := make([]bool, n)
+
+	for i := 0; i < n; {
+		c := query[i]
+		atToken := i == 0 || !isIdentChar(query[i-1])
+
+		start := i
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(query, i+1, c, backslash)
+
+		case atToken && isStringPrefix(c) && i+1 < n && query[i+1] == '\'':
+			i = skipQuoted(query, i+2, '\'', backslash || c == 'E' || c == 'e')
+
+		case atToken && c == '$':
+			if tag, ok := dollarTag(query, i); ok {
+				i = skipDollarQuoted(query, i+len(tag), tag)
+			} else {
+				i++
+				continue
+			}
+
+		case atToken && isDigit(c):
+			i = skipNumber(query, i)
+
+		default:
+			i++
+			continue
+		}
+
+		if i > n {
+			i = n
+		}
+		for k := start; k < i; k++ {
+			mask[k] = true
+		}
+	}
+
+	return mask
+}
+
+// skipQuoted returns the index next to the closing quote of a quoted literal
+// of which content starts at the specified index.
+// A doubled quote is regarded as an escaped quote, and if backslash is true,
+// a backslash escapes the next character.
+func skipQuoted(query string, i int, quote byte, backslash bool) int {
+	n := len(query)
+	for i < n {
+		switch query[i] {
+		case quote:
+			if i+1 < n && query[i+1] == quote {
+				i += 2
+			} else {
+				return i + 1
+			}
+		case '\\':
+			if backslash {
+				i += 2
+			} else {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	return n
+}
+
+// dollarTag returns the opening delimiter of a dollar-quoted string, like "$$"
+// or "$tag$", which starts at the specified index.
+// A positional parameter like "$1" is not a delimiter, because a tag cannot
+// start with a digit.
+func dollarTag(query string, i int) (string, bool) {
+	n := len(query)
+	j := i + 1
+	if j < n && isDigit(query[j]) {
+		return "", false
+	}
+	for j < n && isIdentChar(query[j]) && query[j] != '$' && query[j] != '.' {
+		j++
+	}
+	if j < n && query[j] == '$' {
+		return query[i : j+1], true
+	}
+	return "", false
+}
+
+func skipDollarQuoted(query string, i int, tag string) int {
+	if k := strings.Index(query[i:], tag); k >= 0 {
+		return i + k + len(tag)
+	}
+	return len(query)
+}
+
+func skipNumber(query string, i int) int {
+	n := len(query)
+
+	if query[i] == '0' && i+1 < n {
+		switch query[i+1] {
+		case 'x', 'X', 'b', 'B':
+			i += 2
+			for i < n && isHexDigit(query[i]) {
+				i++
+			}
+			return i
+		}
+	}
+
+	for i < n && (isDigit(query[i]) || query[i] == '.') {
+		i++
+	}
+	if i < n && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+		if j < n && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+		if j < n && isDigit(query[j]) {
+			i = j
+			for i < n && isDigit(query[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isStringPrefix(c byte) bool {
+	switch c {
+	case 'E', 'e', 'B', 'b', 'N', 'n', 'X', 'x':
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func isIdentChar(c byte) bool {
+	return isDigit(c) || c == '_' || c == '$' || c == '.' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,44 @@
+package sqlreasons_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror/sqlreasons"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"", ""},
+		{"SELECT * FROM users", "SELECT * FROM users"},
+		{"SELECT * FROM users WHERE name = 'alice'", "SELECT * FROM users WHERE name = ?"},
+		{"SELECT * FROM users WHERE id = 123", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT * FROM users WHERE score > 1.5", "SELECT * FROM users WHERE score > ?"},
+		{"SELECT * FROM users WHERE id IN (1,2, 3)", "SELECT * FROM users WHERE id IN (?,?, ?)"},
+		{"SELECT col1, t2.col3 FROM t2", "SELECT col1, t2.col3 FROM t2"},
+		{"SELECT * FROM users WHERE id = $1 AND name = ?", "SELECT * FROM users WHERE id = $1 AND name = ?"},
+		{"SELECT * FROM users WHERE name = 'O''Brien'", "SELECT * FROM users WHERE name = ?"},
+		{`SELECT * FROM users WHERE name = E'O\'Brien' AND id = 7`, "SELECT * FROM users WHERE name = ? AND id = ?"},
+		{`INSERT INTO t VALUES ('C:\', 'secret')`, "INSERT INTO t VALUES (?"},
+		{`SELECT * FROM users WHERE name='O\'Brien' AND pw='secret'`, "SELECT * FROM users WHERE name=?"},
+		{`SELECT * FROM users WHERE name="O\"Brien" AND pw="secret"`, "SELECT * FROM users WHERE name=?"},
+		{`SELECT * FROM users WHERE name='a\\' AND pw='secret'`, "SELECT * FROM users WHERE name=? AND pw=?"},
+		{`INSERT INTO t VALUES (e'a\\', 'secret')`, "INSERT INTO t VALUES (?, ?)"},
+		{`SELECT * FROM users WHERE name = "alice" AND note = "say ""hi"""`, "SELECT * FROM users WHERE name = ? AND note = ?"},
+		{"SELECT * FROM t WHERE body = $$it's secret$$ AND id = $1", "SELECT * FROM t WHERE body = ? AND id = $1"},
+		{"SELECT $tag$a $$ b$tag$, $2", "SELECT ?, $2"},
+		{"SELECT * FROM t WHERE x = $$unterminated", "SELECT * FROM t WHERE x = ?"},
+		{"SELECT * FROM t WHERE k = 0xDEADBEEF OR k = 0b1010", "SELECT * FROM t WHERE k = ? OR k = ?"},
+		{"SELECT * FROM t WHERE k = X'DEADBEEF' AND n = N'name'", "SELECT * FROM t WHERE k = ? AND n = ?"},
+		{"SELECT * FROM t WHERE x = 1.5e-3 AND tax = 2E10", "SELECT * FROM t WHERE x = ? AND tax = ?"},
+		{"SELECT col_1e5, ex, t.x1 FROM t", "SELECT col_1e5, ex, t.x1 FROM t"},
+		{"INSERT INTO t VALUES ('a', 'b", "INSERT INTO t VALUES (?, ?"},
+		{"SELECT * FROM t WHERE x = -5", "SELECT * FROM t WHERE x = -?"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, sqlreasons.RedactQuery(test.query), test.expected, test.query)
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package sqlreasons provides error reasons for database/sql errors, and
+// functions to classify errors of database drivers into them.
+//
+// The mappings of driver specific error codes are opt-in. To use them,
+// register the mappers for the drivers with RegisterMapper function:
+//
+//	sqlreasons.RegisterMapper(sqlreasons.PostgresMapper)
+package sqlreasons
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// NoRows is an error reason which indicates that a query returned no row.
+	NoRows struct {
+		Query string
+	}
+
+	// UniqueViolation is an error reason which indicates that a statement
+	// violated a unique or primary key constraint.
+	UniqueViolation struct {
+		Constraint string
+		Query      string
+	}
+
+	// ForeignKeyViolation is an error reason which indicates that a statement
+	// violated a foreign key constraint.
+	ForeignKeyViolation struct {
+		Constraint string
+		Query      string
+	}
+
+	// NotNullViolation is an error reason which indicates that a statement
+	// violated a not-null constraint.
+	NotNullViolation struct {
+		Column string
+		Query  string
+	}
+
+	// CheckViolation is an error reason which indicates that a statement
+	// violated a check constraint.
+	CheckViolation struct {
+		Constraint string
+		Query      string
+	}
+
+	// SerializationFailure is an error reason which indicates that a
+	// transaction could not be serialized with concurrent transactions.
+	SerializationFailure struct {
+		Query string
+	}
+
+	// Deadlock is an error reason which indicates that a deadlock was
+	// detected.
+	Deadlock struct {
+		Query string
+	}
+
+	// LockTimeout is an error reason which indicates that a lock could not be
+	// acquired in time.
+	LockTimeout struct {
+		Query string
+	}
+
+	// ConnectionLost is an error reason which indicates that a connection to
+	// a database was lost or is not usable.
+	ConnectionLost struct {
+		Query string
+	}
+
+	// TxDone is an error reason which indicates that an operation was
+	// performed on a transaction which has already been committed or rolled
+	// back.
+	TxDone struct {
+		Query string
+	}
+
+	// QueryFailed is an error reason which indicates that a query failed for
+	// the other reasons.
+	QueryFailed struct {
+		Query string
+	}
+)
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r SerializationFailure) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r Deadlock) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r LockTimeout) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Class method returns ClassTransient and ClassRetryable.
+func (r ConnectionLost) Class() reasonederror.Class {
+	return reasonederror.ClassTransient | reasonederror.ClassRetryable
+}
+
+// Mapper is a function type which maps an error of a database driver to an
+// error reason.
+// If a Mapper cannot map the error, it returns false as the second result.
+// If the returned reason struct has a string field named Query, WrapSQL
+// function sets the redacted query to it.
+type Mapper func(err error) (reason interface{}, ok bool)
+
+var (
+	mappers    []Mapper
+	mapperLock = sync.RWMutex{}
+)
+
+// RegisterMapper is a function which registers a Mapper used by Classify and
+// WrapSQL functions.
+// Registered Mappers are tried in the order of registration.
+func RegisterMapper(mapper Mapper) {
+	mapperLock.Lock()
+	defer mapperLock.Unlock()
+
+	mappers = append(mappers, mapper)
+}
+
+// Classify is a function which converts the specified error of database/sql
+// into an Err with a reason in this package.
+// The error is set as the cause of the Err.
+//
+// sql.ErrNoRows is classified into NoRows, then the Mappers registered with
+// RegisterMapper function are tried, and driver.ErrBadConn and sql.ErrConnDone
+// are classified into ConnectionLost, sql.ErrTxDone into TxDone, and the
+// others into QueryFailed.
+// If the error is nil, this function returns Ok.
+func Classify(err error) reasonederror.Err {
+	if err == nil {
+		return reasonederror.Ok()
+	}
+	return reasonederror.NewErr(classify(err), err)
+}
+
+// WrapSQL is a function which converts the specified error of database/sql
+// into an Err like Classify function, and sets the specified query redacted
+// with RedactQuery function to the Query field of the reason.
+// Because a text of a driver error often contains values in the query, the
+// cause of the Err is an error of which text is redacted with RedactQuery
+// function and which wraps the original error.
+// Therefore the original error can be obtained with errors.Is and errors.As
+// functions.
+// If the error is nil, this function returns Ok.
+func WrapSQL(err error, query string) reasonederror.Err {
+	if err == nil {
+		return reasonederror.Ok()
+	}
+	reason := withQuery(classify(err), RedactQuery(query))
+	return reasonederror.NewErr(reason, redactedError{err})
+}
+
+// redactedError is an error which wraps a driver error and redacts the
+// literals in its text.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return RedactQuery(e.err.Error())
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+func classify(err error) interface{} {
+	if errors.Is(err, sql.ErrNoRows) {
+		return NoRows{}
+	}
+
+	mapperLock.RLock()
+	ms := mappers
+	mapperLock.RUnlock()
+
+	for _, m := range ms {
+		if reason, ok := m(err); ok {
+			return reason
+		}
+	}
+
+	switch {
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return ConnectionLost{}
+	case errors.Is(err, sql.ErrTxDone):
+		return TxDone{}
+	default:
+		return QueryFailed{}
+	}
+}
+
+func withQuery(reason interface{}, query string) interface{} {
+	v := reflect.ValueOf(reason)
+	if v.Kind() != reflect.Struct {
+		return reason
+	}
+
+	nv := reflect.New(v.Type()).Elem()
+	nv.Set(v)
+
+	f := nv.FieldByName("Query")
+	if !f.IsValid() || f.Kind() != reflect.String || !f.CanSet() {
+		return reason
+	}
+	f.SetString(query)
+
+	return nv.Interface()
+}
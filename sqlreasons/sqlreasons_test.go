@@ -0,0 +1,246 @@
+package sqlreasons_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/sqlreasons"
+)
+
+type /* error reasons */ (
+	DuplicateOrder struct {
+		Query string
+	}
+	OrderLocked struct{}
+)
+
+var errDuplicateOrder = errors.New("duplicate order")
+var errOrderLocked = errors.New("order locked")
+
+func init() {
+	sqlreasons.RegisterMapper(func(err error) (interface{}, bool) {
+		switch {
+		case errors.Is(err, errDuplicateOrder):
+			return DuplicateOrder{}, true
+		case errors.Is(err, errOrderLocked):
+			return OrderLocked{}, true
+		default:
+			return nil, false
+		}
+	})
+}
+
+// fakeDriver is a database/sql driver which returns the error of a query
+// string starting with "error:" and otherwise returns no row.
+type fakeDriver struct{}
+type fakeConn struct{}
+type fakeStmt struct{ query string }
+type fakeRows struct{}
+
+var fakeErrors = map[string]error{}
+
+func (d fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c fakeConn) Commit() error                             { return nil }
+func (c fakeConn) Rollback() error                           { return nil }
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if e, ok := fakeErrors[s.query]; ok {
+		return nil, e
+	}
+	return driver.RowsAffected(0), nil
+}
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if e, ok := fakeErrors[s.query]; ok {
+		return nil, e
+	}
+	return fakeRows{}, nil
+}
+
+func (r fakeRows) Columns() []string              { return []string{"id"} }
+func (r fakeRows) Close() error                   { return nil }
+func (r fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func init() {
+	sql.Register("sqlreasons_fake", fakeDriver{})
+}
+
+func openDB(t *testing.T) *sql.DB {
+	db, e := sql.Open("sqlreasons_fake", "")
+	assert.Nil(t, e)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestClassify_nil(t *testing.T) {
+	assert.True(t, sqlreasons.Classify(nil).IsOk())
+	assert.True(t, sqlreasons.WrapSQL(nil, "SELECT 1").IsOk())
+}
+
+func TestClassify_noRows(t *testing.T) {
+	db := openDB(t)
+
+	var id int
+	e := db.QueryRow("SELECT id FROM users WHERE id = 1").Scan(&id)
+
+	err := sqlreasons.Classify(e)
+	switch r := err.Reason().(type) {
+	case sqlreasons.NoRows:
+		assert.Equal(t, r.Query, "")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause(), e)
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func TestWrapSQL_noRows(t *testing.T) {
+	db := openDB(t)
+
+	query := "SELECT id FROM users WHERE name = 'alice' AND age > 20"
+	var id int
+	e := db.QueryRow(query).Scan(&id)
+
+	err := sqlreasons.WrapSQL(e, query)
+	switch r := err.Reason().(type) {
+	case sqlreasons.NoRows:
+		assert.Equal(t, r.Query, "SELECT id FROM users WHERE name = ? AND age > ?")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func TestWrapSQL_connectionLost(t *testing.T) {
+	db := openDB(t)
+
+	query := "UPDATE users SET name = 'bob'"
+	fakeErrors[query] = driver.ErrBadConn
+	defer delete(fakeErrors, query)
+
+	_, e := db.Exec(query)
+
+	err := sqlreasons.WrapSQL(e, query)
+	switch r := err.Reason().(type) {
+	case sqlreasons.ConnectionLost:
+		assert.Equal(t, r.Query, "UPDATE users SET name = ?")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, err.IsTransient())
+	assert.True(t, err.IsRetryable())
+
+	err = sqlreasons.Classify(sql.ErrConnDone)
+	assert.Equal(t, err.ReasonName(), "ConnectionLost")
+}
+
+func TestClassify_txDone(t *testing.T) {
+	db := openDB(t)
+
+	tx, e := db.BeginTx(context.Background(), nil)
+	assert.Nil(t, e)
+	assert.Nil(t, tx.Commit())
+
+	e = tx.Commit()
+	err := sqlreasons.Classify(e)
+	switch err.Reason().(type) {
+	case sqlreasons.TxDone:
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.False(t, err.IsRetryable())
+}
+
+func TestClassify_queryFailed(t *testing.T) {
+	db := openDB(t)
+
+	query := "SELEC 1"
+	fakeErrors[query] = errors.New("syntax error")
+	defer delete(fakeErrors, query)
+
+	_, e := db.Query(query)
+
+	err := sqlreasons.WrapSQL(e, query)
+	switch r := err.Reason().(type) {
+	case sqlreasons.QueryFailed:
+		assert.Equal(t, r.Query, "SELEC ?")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause().Error(), "syntax error")
+	assert.False(t, err.IsRetryable())
+}
+
+func TestRegisterMapper(t *testing.T) {
+	e := fmt.Errorf("insert: %w", errDuplicateOrder)
+
+	err := sqlreasons.WrapSQL(e, "INSERT INTO orders VALUES (1)")
+	switch r := err.Reason().(type) {
+	case DuplicateOrder:
+		assert.Equal(t, r.Query, "INSERT INTO orders VALUES (?)")
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, errors.Unwrap(err.Cause()), e)
+	assert.True(t, errors.Is(err, errDuplicateOrder))
+
+	err = sqlreasons.WrapSQL(errOrderLocked, "SELECT 1")
+	switch err.Reason().(type) {
+	case OrderLocked:
+	default:
+		assert.Fail(t, err.Error())
+	}
+
+	err = sqlreasons.Classify(errDuplicateOrder)
+	assert.Equal(t, err.Reason(), DuplicateOrder{})
+}
+
+func TestReasons_class(t *testing.T) {
+	retryables := []interface{}{
+		sqlreasons.SerializationFailure{},
+		sqlreasons.Deadlock{},
+		sqlreasons.LockTimeout{},
+		sqlreasons.ConnectionLost{},
+	}
+	for _, r := range retryables {
+		err := reasonederror.NewErr(r)
+		assert.True(t, err.IsTransient(), err.ReasonName())
+		assert.True(t, err.IsRetryable(), err.ReasonName())
+	}
+
+	others := []interface{}{
+		sqlreasons.NoRows{},
+		sqlreasons.UniqueViolation{},
+		sqlreasons.ForeignKeyViolation{},
+		sqlreasons.NotNullViolation{},
+		sqlreasons.CheckViolation{},
+		sqlreasons.TxDone{},
+		sqlreasons.QueryFailed{},
+	}
+	for _, r := range others {
+		err := reasonederror.NewErr(r)
+		assert.False(t, err.IsRetryable(), err.ReasonName())
+	}
+}
+
+func TestWrapSQL_redactsCause(t *testing.T) {
+	e := fmt.Errorf("Duplicate entry 'a@b.c' for key 'users.email': %w", errDuplicateOrder)
+
+	err := sqlreasons.WrapSQL(e, "INSERT INTO users (email) VALUES ('a@b.c')")
+	assert.Equal(t, err.Cause().Error(), "Duplicate entry ? for key ?: duplicate order")
+	assert.False(t, strings.Contains(err.Error(), "a@b.c"))
+	assert.True(t, errors.Is(err, errDuplicateOrder))
+}
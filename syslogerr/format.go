@@ -0,0 +1,236 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package syslogerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sttk/reasonederror"
+)
+
+// Facility is a type which represents a syslog facility.
+type Facility int
+
+// Facilities of syslog messages defined in RFC 5424.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// DefaultEnterpriseID is the private enterprise number used in SD-IDs of
+// structured data elements by default, which is reserved for documentation
+// by RFC 5612.
+const DefaultEnterpriseID = 32473
+
+const (
+	nilValue   = "-"
+	timeLayout = "2006-01-02T15:04:05.000000Z07:00"
+	utf8BOM    = "\xef\xbb\xbf"
+
+	maxHostname = 255
+	maxAppName  = 48
+	maxProcID   = 128
+	maxMsgID    = 32
+	maxSDName   = 32
+)
+
+// severities of syslog messages.
+const (
+	severityCrit  = 2
+	severityErr   = 3
+	severityWarn  = 4
+	severityInfo  = 6
+	severityDebug = 7
+)
+
+// Priority is a function which returns the PRI value of a syslog message for
+// the specified facility and severity.
+// SeverityFatal is mapped to Critical, SeverityError to Error, SeverityWarn to
+// Warning, SeverityInfo to Informational, and SeverityDebug to Debug.
+func Priority(facility Facility, severity reasonederror.Severity) int {
+	var s int
+	switch {
+	case severity >= reasonederror.SeverityFatal:
+		s = severityCrit
+	case severity >= reasonederror.SeverityError:
+		s = severityErr
+	case severity >= reasonederror.SeverityWarn:
+		s = severityWarn
+	case severity >= reasonederror.SeverityInfo:
+		s = severityInfo
+	default:
+		s = severityDebug
+	}
+	return int(facility)*8 + s
+}
+
+// Header is a struct which has the header fields of syslog messages other
+// than PRI and TIMESTAMP.
+type Header struct {
+	Hostname     string
+	AppName      string
+	ProcID       string
+	EnterpriseID int
+}
+
+// Format is a function which returns a syslog message of RFC 5424 for the
+// specified Err and ErrOccasion.
+//
+// The MSGID is the name of the reason, and the MSG is the result of
+// RedactedMessage method of the Err if it is not empty, or the name of the
+// reason.
+// The message has structured data elements: "reason@<EnterpriseID>" of
+// which parameters are name, package, severity, file, line and fingerprint,
+// "situation@<EnterpriseID>" of which parameters are the redacted situation
+// of the Err, and "attrs@<EnterpriseID>" of which parameters are the
+// attributes of the ErrOccasion.
+// The elements which have no parameter are omitted.
+// The characters which are not allowed in parameter names are replaced with
+// "_", and string values are written as they are and other values as JSON.
+func Format(
+	facility Facility, h Header, err reasonederror.Err, occ reasonederror.ErrOccasion,
+) []byte {
+	if h.EnterpriseID <= 0 {
+		h.EnterpriseID = DefaultEnterpriseID
+	}
+	ent := "@" + strconv.Itoa(h.EnterpriseID)
+
+	var b strings.Builder
+
+	b.WriteString("<")
+	b.WriteString(strconv.Itoa(Priority(facility, err.Severity())))
+	b.WriteString(">1 ")
+
+	if t := occ.Time(); t.IsZero() {
+		b.WriteString(nilValue)
+	} else {
+		b.WriteString(t.Format(timeLayout))
+	}
+	b.WriteString(" ")
+	b.WriteString(headerField(h.Hostname, maxHostname))
+	b.WriteString(" ")
+	b.WriteString(headerField(h.AppName, maxAppName))
+	b.WriteString(" ")
+	b.WriteString(headerField(h.ProcID, maxProcID))
+	b.WriteString(" ")
+	b.WriteString(headerField(err.ReasonName(), maxMsgID))
+	b.WriteString(" ")
+
+	b.WriteString("[reason" + ent)
+	writeParam(&b, "name", err.ReasonName())
+	writeParam(&b, "package", err.ReasonPackage())
+	writeParam(&b, "severity", err.Severity().String())
+	if occ.File() != "" {
+		writeParam(&b, "file", occ.File())
+		writeParam(&b, "line", strconv.Itoa(occ.Line()))
+	}
+	writeParam(&b, "fingerprint", err.Fingerprint())
+	b.WriteString("]")
+
+	writeElement(&b, "situation"+ent, err.RedactedSituation())
+	writeElement(&b, "attrs"+ent, occ.Attrs())
+
+	msg := err.RedactedMessage()
+	if msg == "" {
+		msg = err.ReasonName()
+	}
+	b.WriteString(" ")
+	b.WriteString(utf8BOM)
+	b.WriteString(msg)
+
+	return []byte(b.String())
+}
+
+func headerField(s string, max int) string {
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < max; i++ {
+		if c := s[i]; c >= 33 && c <= 126 {
+			b.WriteByte(c)
+		}
+	}
+	if b.Len() == 0 {
+		return nilValue
+	}
+	return b.String()
+}
+
+func sdName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < maxSDName; i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		b.WriteByte(c)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+func paramValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	if b, e := json.Marshal(v); e == nil {
+		return string(b)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+var paramValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func writeElement(b *strings.Builder, id string, params map[string]interface{}) {
+	if len(params) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("[" + id)
+	for _, k := range keys {
+		writeParam(b, sdName(k), paramValue(params[k]))
+	}
+	b.WriteString("]")
+}
+
+func writeParam(b *strings.Builder, name, value string) {
+	b.WriteString(" ")
+	b.WriteString(name)
+	b.WriteString(`="`)
+	paramValueEscaper.WriteString(b, value)
+	b.WriteString(`"`)
+}
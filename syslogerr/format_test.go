@@ -0,0 +1,117 @@
+package syslogerr_test
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/syslogerr"
+)
+
+type /* error reasons */ (
+	FailToConnect struct {
+		Host     string
+		Port     int
+		Password string `redact:"true"`
+	}
+	FailToParse struct {
+		Text string
+	}
+	FailToStart struct{}
+	CacheMissed struct{}
+)
+
+func (r FailToStart) Severity() reasonederror.Severity {
+	return reasonederror.SeverityFatal
+}
+
+func (r FailToStart) Message() string {
+	return "could not start the server"
+}
+
+func (r CacheMissed) Severity() reasonederror.Severity {
+	return reasonederror.SeverityInfo
+}
+
+var lastOcc reasonederror.ErrOccasion
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastOcc = occ
+	})
+	reasonederror.SetReasonMessage(FailToConnect{}, "could not connect to {{.Host}} with {{.Password}}")
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+var header = syslogerr.Header{
+	Hostname: "host1",
+	AppName:  "app",
+	ProcID:   "123",
+}
+
+func TestPriority(t *testing.T) {
+	f := syslogerr.FacilityLocal0
+	assert.Equal(t, syslogerr.Priority(f, reasonederror.SeverityFatal), 130)
+	assert.Equal(t, syslogerr.Priority(f, reasonederror.SeverityError), 131)
+	assert.Equal(t, syslogerr.Priority(f, reasonederror.SeverityWarn), 132)
+	assert.Equal(t, syslogerr.Priority(f, reasonederror.SeverityInfo), 134)
+	assert.Equal(t, syslogerr.Priority(f, reasonederror.SeverityDebug), 135)
+	assert.Equal(t, syslogerr.Priority(syslogerr.FacilityUser, reasonederror.SeverityError), 11)
+}
+
+func TestFormat(t *testing.T) {
+	err := reasonederror.NewErr(FailToConnect{Host: "db1", Port: 5432, Password: "secret"})
+	occ := lastOcc
+
+	msg := string(syslogerr.Format(syslogerr.FacilityLocal0, header, err, occ))
+
+	assert.Equal(t, msg, "<131>1 "+occ.Time().Format("2006-01-02T15:04:05.000000Z07:00")+
+		" host1 app 123 FailToConnect"+
+		` [reason@32473 name="FailToConnect" package="github.com/sttk/reasonederror/syslogerr_test"`+
+		` severity="error" file="format_test.go" line="`+strconv.Itoa(occ.Line())+`"`+
+		` fingerprint="`+err.Fingerprint()+`"]`+
+		`[situation@32473 Host="db1" Password="[REDACTED\]" Port="5432"]`+
+		" \xef\xbb\xbfcould not connect to db1 with [REDACTED]")
+	assert.False(t, strings.Contains(msg, "secret"))
+}
+
+func TestFormat_message(t *testing.T) {
+	h := header
+	h.EnterpriseID = 99999
+	h.Hostname = ""
+	h.AppName = "my app"
+
+	err := reasonederror.NewErr(FailToStart{})
+	msg := string(syslogerr.Format(syslogerr.FacilityDaemon, h, err, reasonederror.ErrOccasion{}))
+
+	assert.Equal(t, msg, "<26>1 - - myapp 123 FailToStart"+
+		` [reason@99999 name="FailToStart" package="github.com/sttk/reasonederror/syslogerr_test"`+
+		` severity="fatal" fingerprint="`+err.Fingerprint()+`"]`+
+		" \xef\xbb\xbfcould not start the server")
+}
+
+func TestFormat_escape(t *testing.T) {
+	err := reasonederror.NewErr(FailToParse{Text: `a "b" [c] \d`})
+	msg := string(syslogerr.Format(syslogerr.FacilityUser, header, err, lastOcc))
+
+	assert.True(t, strings.HasSuffix(msg,
+		`[situation@32473 Text="a \"b\" [c\] \\d"] `+"\xef\xbb\xbfFailToParse"), msg)
+}
+
+func TestFormat_attrs(t *testing.T) {
+	occ := lastOcc
+	occ.SetAttr("service", "api")
+	occ.SetAttr("build commit=", "abc]")
+	occ.SetAttr("replicas", 3)
+
+	msg := string(syslogerr.Format(syslogerr.FacilityUser, header, reasonederror.NewErr(FailToStart{}), occ))
+
+	assert.True(t, strings.HasSuffix(msg,
+		`[attrs@32473 build_commit_="abc\]" replicas="3" service="api"] `+
+			"\xef\xbb\xbfcould not start the server"), msg)
+}
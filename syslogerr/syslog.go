@@ -0,0 +1,298 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package syslogerr provides an Err notification handler which writes
+// notifications as syslog messages of RFC 5424 to a local syslog daemon or a
+// remote syslog server.
+//
+//	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+//	    Network:  "tcp",
+//	    Address:  "logs.example.com:601",
+//	    Facility: syslogerr.FacilityLocal0,
+//	})
+//	if err.IsNotOk() {
+//	    ...
+//	}
+//	defer sl.Close()
+//
+//	reasonederror.AddAsyncErrHandler(sl.Handle)
+//	reasonederror.FixErrCfgs()
+//
+// The priority of a message is derived from the severity of the Err, and the
+// reason, package and situation of the Err are written as structured data
+// elements. See Format function for details.
+package syslogerr
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// FailToConnect is an error reason which indicates that a Syslog failed to
+	// connect to a syslog daemon or server.
+	FailToConnect struct {
+		Network string
+		Address string
+	}
+
+	// FailToSend is an error reason which indicates that a Syslog failed to
+	// send some messages.
+	// The cause is the first error which occurred.
+	FailToSend struct {
+		Network  string
+		Address  string
+		Messages int
+	}
+)
+
+const defaultTimeout = 5 * time.Second
+
+// Paths of the local syslog socket, which are searched in this order when
+// Network option is empty.
+var localSocketPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Options is a struct which configures a Syslog.
+type Options struct {
+	// Network is the network to connect, which is "unixgram", "unix", "udp"
+	// or "tcp".
+	// If this is empty, the local syslog daemon is connected through a unix
+	// socket, like "/dev/log".
+	Network string
+
+	// Address is the address to connect.
+	// If Network is empty, this is ignored.
+	Address string
+
+	// Facility is the facility of messages.
+	// If this is zero, FacilityUser is used, because FacilityKern is reserved
+	// for kernel messages.
+	Facility Facility
+
+	// Hostname is the HOSTNAME field of messages.
+	// If this is empty, the result of os.Hostname is used.
+	Hostname string
+
+	// AppName is the APP-NAME field of messages.
+	// If this is empty, the base name of the executable is used.
+	AppName string
+
+	// EnterpriseID is the private enterprise number used in SD-IDs.
+	// If this is zero or less, DefaultEnterpriseID is used.
+	EnterpriseID int
+
+	// MinSeverity is the minimum severity of Err(s) to be written.
+	// Because the zero value is SeverityError, Err(s) of which severities are
+	// lower than SeverityError are not written by default.
+	MinSeverity reasonederror.Severity
+
+	// Timeout is the time limit to connect and to write a message.
+	// If this is zero or less, 5 seconds is used.
+	Timeout time.Duration
+
+	// OnDrop is the function which is called when a message could not be
+	// written.
+	// This is useful to count drops, like with metrics.Metrics.Dropped.
+	OnDrop func()
+
+	// OnError is the function which is called when an attempt to connect or
+	// to write a message fails.
+	OnError func(error)
+}
+
+// Syslog is a struct which writes Err notifications as syslog messages.
+//
+// On "tcp" connections, messages are framed with octet counting of RFC 6587.
+// On "unix" stream connections, like the local socket of a syslog daemon,
+// messages are terminated with a newline, because local daemons do not
+// expect octet counting.
+// When writing a message fails, Syslog reconnects and writes it again once.
+// If the connection cannot be made, the message is dropped, and the
+// connection is retried for the next message.
+type Syslog struct {
+	opts    Options
+	header  Header
+	network string
+	address string
+
+	mutex    sync.Mutex
+	conn     net.Conn
+	closed   bool
+	failed   int
+	firstErr error
+}
+
+// NewSyslog is a function which creates a Syslog and connects to a syslog
+// daemon or server.
+// If the connection cannot be made, this function returns an Err of which
+// reason is FailToConnect.
+func NewSyslog(opts Options) (*Syslog, reasonederror.Err) {
+	if opts.Facility == FacilityKern {
+		opts.Facility = FacilityUser
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.AppName == "" {
+		opts.AppName = filepath.Base(os.Args[0])
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	s := &Syslog{
+		opts: opts,
+		header: Header{
+			Hostname:     opts.Hostname,
+			AppName:      opts.AppName,
+			ProcID:       strconv.Itoa(os.Getpid()),
+			EnterpriseID: opts.EnterpriseID,
+		},
+		network: opts.Network,
+		address: opts.Address,
+	}
+
+	if e := s.connect(); e != nil {
+		return nil, reasonederror.NewErr(FailToConnect{
+			Network: opts.Network,
+			Address: opts.Address,
+		}, e)
+	}
+	return s, reasonederror.Ok()
+}
+
+// Handle method writes a syslog message of the specified Err.
+// If the severity of the Err is lower than MinSeverity, it is ignored.
+// This method is used as an Err notification handler, and is safe for
+// concurrent use.
+// Because this method waits for writing, it should be registered with
+// AddAsyncErrHandler function if the connection may be slow.
+func (s *Syslog) Handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	if err.Severity() < s.opts.MinSeverity {
+		return
+	}
+
+	msg := Format(s.opts.Facility, s.header, err, occ)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		s.drop()
+		return
+	}
+
+	e := s.write(msg)
+	if e != nil {
+		s.onError(e)
+		s.disconnect()
+		e = s.write(msg)
+	}
+	if e == nil {
+		return
+	}
+	s.onError(e)
+	s.disconnect()
+
+	s.failed++
+	if s.firstErr == nil {
+		s.firstErr = e
+	}
+	s.drop()
+}
+
+// Close method closes the connection and stops this Syslog.
+// If some messages could not be written, this method returns an Err of which
+// reason is FailToSend.
+func (s *Syslog) Close() reasonederror.Err {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.closed = true
+	s.disconnect()
+
+	if s.failed > 0 {
+		return reasonederror.NewErr(FailToSend{
+			Network:  s.network,
+			Address:  s.address,
+			Messages: s.failed,
+		}, s.firstErr)
+	}
+	return reasonederror.Ok()
+}
+
+func (s *Syslog) isTCP() bool {
+	return s.network == "tcp" || s.network == "tcp4" || s.network == "tcp6"
+}
+
+func (s *Syslog) connect() error {
+	if s.opts.Network != "" {
+		conn, e := net.DialTimeout(s.opts.Network, s.opts.Address, s.opts.Timeout)
+		if e != nil {
+			return e
+		}
+		s.conn = conn
+		return nil
+	}
+
+	var err error
+	for _, path := range localSocketPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, e := net.DialTimeout(network, path, s.opts.Timeout)
+			if e != nil {
+				if err == nil {
+					err = e
+				}
+				continue
+			}
+			s.conn = conn
+			s.network = network
+			s.address = path
+			return nil
+		}
+	}
+	return err
+}
+
+func (s *Syslog) disconnect() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *Syslog) write(msg []byte) error {
+	if s.conn == nil {
+		if e := s.connect(); e != nil {
+			return e
+		}
+	}
+	switch {
+	case s.isTCP():
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	case s.network == "unix":
+		msg = append(msg, '\n')
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.opts.Timeout))
+	_, e := s.conn.Write(msg)
+	return e
+}
+
+func (s *Syslog) onError(e error) {
+	if s.opts.OnError != nil {
+		s.opts.OnError(e)
+	}
+}
+
+func (s *Syslog) drop() {
+	if s.opts.OnDrop != nil {
+		s.opts.OnDrop()
+	}
+}
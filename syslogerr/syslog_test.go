@@ -0,0 +1,276 @@
+package syslogerr_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/syslogerr"
+)
+
+// readFrame reads a message framed with octet counting.
+func readFrame(r *bufio.Reader) (string, error) {
+	n, e := r.ReadString(' ')
+	if e != nil {
+		return "", e
+	}
+	size, e := strconv.Atoi(strings.TrimSuffix(n, " "))
+	if e != nil {
+		return "", e
+	}
+	b := make([]byte, size)
+	_, e = io.ReadFull(r, b)
+	return string(b), e
+}
+
+func readPacket(t *testing.T, pc net.PacketConn) string {
+	b := make([]byte, 4096)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, e := pc.ReadFrom(b)
+	assert.Nil(t, e)
+	return string(b[:n])
+}
+
+func TestSyslog_udp(t *testing.T) {
+	pc, e := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	defer pc.Close()
+
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+		Network:  "udp",
+		Address:  pc.LocalAddr().String(),
+		Facility: syslogerr.FacilityLocal0,
+		Hostname: "host1",
+		AppName:  "app",
+	})
+	assert.True(t, err.IsOk())
+
+	sl.Handle(reasonederror.NewErr(CacheMissed{}), lastOcc)
+	sl.Handle(reasonederror.NewErr(FailToConnect{Host: "db1"}), lastOcc)
+
+	msg := readPacket(t, pc)
+	assert.True(t, strings.HasPrefix(msg, "<131>1 "), msg)
+	assert.True(t, strings.Contains(msg, " host1 app "), msg)
+	assert.True(t, strings.Contains(msg, `[situation@32473 Host="db1" Password="[REDACTED\]" Port="0"]`), msg)
+
+	assert.True(t, sl.Close().IsOk())
+}
+
+func TestSyslog_minSeverity(t *testing.T) {
+	pc, e := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	defer pc.Close()
+
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+		Network:     "udp",
+		Address:     pc.LocalAddr().String(),
+		MinSeverity: reasonederror.SeverityDebug,
+	})
+	assert.True(t, err.IsOk())
+	defer sl.Close()
+
+	sl.Handle(reasonederror.NewErr(CacheMissed{}), lastOcc)
+
+	msg := readPacket(t, pc)
+	assert.True(t, strings.HasPrefix(msg, "<14>1 "), msg)
+	assert.True(t, strings.Contains(msg, " CacheMissed "), msg)
+}
+
+func TestSyslog_unixgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	pc, e := net.ListenPacket("unixgram", path)
+	assert.Nil(t, e)
+	defer pc.Close()
+
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+		Network: "unixgram",
+		Address: path,
+	})
+	assert.True(t, err.IsOk())
+	defer sl.Close()
+
+	sl.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+
+	msg := readPacket(t, pc)
+	assert.True(t, strings.HasPrefix(msg, "<10>1 "), msg)
+	assert.True(t, strings.HasSuffix(msg, "could not start the server"), msg)
+}
+
+func TestSyslog_unixStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	ln, e := net.Listen("unix", path)
+	assert.Nil(t, e)
+	defer ln.Close()
+
+	received := make(chan string, 10)
+	go func() {
+		conn, e := ln.Accept()
+		if e != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			line, e := r.ReadString('\n')
+			if e != nil {
+				return
+			}
+			received <- line
+		}
+	}()
+
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+		Network: "unix",
+		Address: path,
+	})
+	assert.True(t, err.IsOk())
+	defer sl.Close()
+
+	sl.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	sl.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			assert.True(t, strings.HasPrefix(msg, "<10>1 "), msg)
+			assert.True(t, strings.HasSuffix(msg, "could not start the server\n"), msg)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "no message")
+		}
+	}
+}
+
+func TestSyslog_tcpReconnect(t *testing.T) {
+	ln, e := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	defer ln.Close()
+
+	var mutex sync.Mutex
+	var conns int
+	received := make(chan string, 100)
+	go func() {
+		for {
+			conn, e := ln.Accept()
+			if e != nil {
+				return
+			}
+			mutex.Lock()
+			conns++
+			first := conns == 1
+			mutex.Unlock()
+
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					msg, e := readFrame(r)
+					if e != nil {
+						return
+					}
+					received <- msg
+					if first {
+						// drops the first connection after a message.
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	var errs int
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+		Network: "tcp",
+		Address: ln.Addr().String(),
+		OnError: func(error) { errs++ },
+	})
+	assert.True(t, err.IsOk())
+
+	sl.Handle(reasonederror.NewErr(FailToConnect{Host: "db1"}), lastOcc)
+	msg := <-received
+	assert.True(t, strings.HasPrefix(msg, "<11>1 "), msg)
+	assert.True(t, strings.Contains(msg, `Host="db1"`), msg)
+
+	// Writes to the dropped connection may succeed until the peer's reset is
+	// received, so messages are written until one arrives again.
+	deadline := time.After(5 * time.Second)
+loop:
+	for {
+		sl.Handle(reasonederror.NewErr(FailToConnect{Host: "db2"}), lastOcc)
+		select {
+		case msg = <-received:
+			break loop
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline:
+			assert.Fail(t, "no message after reconnection")
+			break loop
+		}
+	}
+	assert.True(t, strings.Contains(msg, `Host="db2"`), msg)
+
+	mutex.Lock()
+	assert.Equal(t, conns, 2)
+	mutex.Unlock()
+	assert.True(t, errs >= 1)
+
+	assert.True(t, sl.Close().IsOk())
+}
+
+func TestNewSyslog_failToConnect(t *testing.T) {
+	ln, e := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, e)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{Network: "tcp", Address: addr})
+	assert.Nil(t, sl)
+	switch r := err.Reason().(type) {
+	case syslogerr.FailToConnect:
+		assert.Equal(t, r.Network, "tcp")
+		assert.Equal(t, r.Address, addr)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.NotNil(t, err.Cause())
+}
+
+func TestSyslog_failToSend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log")
+	pc, e := net.ListenPacket("unixgram", path)
+	assert.Nil(t, e)
+
+	var dropped int
+	sl, err := syslogerr.NewSyslog(syslogerr.Options{
+		Network: "unixgram",
+		Address: path,
+		OnDrop:  func() { dropped++ },
+	})
+	assert.True(t, err.IsOk())
+
+	pc.Close()
+
+	sl.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	sl.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+
+	err = sl.Close()
+	switch r := err.Reason().(type) {
+	case syslogerr.FailToSend:
+		assert.Equal(t, r.Network, "unixgram")
+		assert.Equal(t, r.Address, path)
+		assert.Equal(t, r.Messages, 2)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.NotNil(t, err.Cause())
+	assert.Equal(t, dropped, 2)
+
+	sl.Handle(reasonederror.NewErr(FailToStart{}), lastOcc)
+	assert.Equal(t, dropped, 3)
+}
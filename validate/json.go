@@ -0,0 +1,60 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package validate
+
+import (
+	"errors"
+
+	"github.com/sttk/reasonederror"
+)
+
+// FieldError is a struct which represents a failure of a field for JSON
+// rendering.
+// Reason is the name of the reason type, Message is the result of
+// RedactedMessage method of the Err or the reason name if it is empty, and
+// Params is the redacted situation of the Err except Field.
+type FieldError struct {
+	Field   string                 `json:"field"`
+	Reason  string                 `json:"reason"`
+	Message string                 `json:"message"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+}
+
+// FieldErrors is a function which returns the failures of fields in the
+// specified Err returned by Struct function as a slice of FieldError.
+// If the Err is not of InvalidFields, this function returns nil.
+func FieldErrors(err reasonederror.Err) []FieldError {
+	if _, ok := err.Reason().(InvalidFields); !ok {
+		return nil
+	}
+
+	var errs reasonederror.Errs
+	if !errors.As(err.Cause(), &errs) {
+		return nil
+	}
+
+	a := make([]FieldError, 0, len(errs))
+	for _, e := range errs {
+		params := e.RedactedSituation()
+		field, _ := params["Field"].(string)
+		delete(params, "Field")
+		if len(params) == 0 {
+			params = nil
+		}
+
+		msg := e.RedactedMessage()
+		if msg == "" {
+			msg = e.ReasonName()
+		}
+
+		a = append(a, FieldError{
+			Field:   field,
+			Reason:  e.ReasonName(),
+			Message: msg,
+			Params:  params,
+		})
+	}
+	return a
+}
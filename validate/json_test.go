@@ -0,0 +1,63 @@
+package validate_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/validate"
+)
+
+func TestFieldErrors(t *testing.T) {
+	type Form struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"email"`
+		Tags  []int  `json:"tags" validate:"max=1"`
+	}
+
+	err := validate.Struct(Form{Email: "x", Tags: []int{1, 2}})
+	fes := validate.FieldErrors(err)
+
+	assert.Equal(t, fes, []validate.FieldError{
+		{Field: "name", Reason: "Required", Message: "name is required"},
+		{Field: "email", Reason: "InvalidFormat", Message: "email must be a valid email",
+			Params: map[string]interface{}{"Format": "email"}},
+		{Field: "tags", Reason: "TooLong", Message: "tags must be at most 1 in length",
+			Params: map[string]interface{}{"Max": 1, "Actual": 2}},
+	})
+
+	b, e := json.Marshal(fes)
+	assert.Nil(t, e)
+	assert.Equal(t, string(b), `[`+
+		`{"field":"name","reason":"Required","message":"name is required"},`+
+		`{"field":"email","reason":"InvalidFormat","message":"email must be a valid email","params":{"Format":"email"}},`+
+		`{"field":"tags","reason":"TooLong","message":"tags must be at most 1 in length","params":{"Actual":2,"Max":1}}`+
+		`]`)
+}
+
+type /* error reasons */ (
+	WeakPassword struct {
+		Field string
+		Value string `redact:"true"`
+	}
+)
+
+func TestFieldErrors_redactedMessage(t *testing.T) {
+	reasonederror.SetReasonMessage(WeakPassword{}, "{{.Field}} is too weak: {{.Value}}")
+
+	err := reasonederror.NewErr(validate.InvalidFields{Count: 1}, reasonederror.Errs{
+		reasonederror.NewErr(WeakPassword{Field: "password", Value: "secret"}),
+	})
+
+	assert.Equal(t, validate.FieldErrors(err), []validate.FieldError{
+		{Field: "password", Reason: "WeakPassword", Message: "password is too weak: [REDACTED]",
+			Params: map[string]interface{}{"Value": reasonederror.RedactedValue}},
+	})
+}
+
+func TestFieldErrors_notInvalidFields(t *testing.T) {
+	assert.Nil(t, validate.FieldErrors(reasonederror.Ok()))
+	assert.Nil(t, validate.FieldErrors(validate.Struct("x")))
+	assert.Nil(t, validate.FieldErrors(reasonederror.NewErr(validate.InvalidFields{Count: 1})))
+}
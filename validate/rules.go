@@ -0,0 +1,253 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+package validate
+
+import (
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// Required is an error reason which indicates that a required field is
+	// empty.
+	Required struct {
+		Field string `json:"field"`
+	}
+
+	// TooShort is an error reason which indicates that the length of a string,
+	// a slice, an array or a map is less than the minimum.
+	// The length of a string is counted in runes.
+	TooShort struct {
+		Field  string `json:"field"`
+		Min    int    `json:"min"`
+		Actual int    `json:"actual"`
+	}
+
+	// TooLong is an error reason which indicates that the length of a string,
+	// a slice, an array or a map is greater than the maximum.
+	// The length of a string is counted in runes.
+	TooLong struct {
+		Field  string `json:"field"`
+		Max    int    `json:"max"`
+		Actual int    `json:"actual"`
+	}
+
+	// TooSmall is an error reason which indicates that a number is less than
+	// the minimum.
+	TooSmall struct {
+		Field  string  `json:"field"`
+		Min    float64 `json:"min"`
+		Actual float64 `json:"actual"`
+	}
+
+	// TooLarge is an error reason which indicates that a number is greater
+	// than the maximum.
+	TooLarge struct {
+		Field  string  `json:"field"`
+		Max    float64 `json:"max"`
+		Actual float64 `json:"actual"`
+	}
+
+	// InvalidFormat is an error reason which indicates that a string is not in
+	// the required format, like "email" or "url".
+	InvalidFormat struct {
+		Field  string `json:"field"`
+		Format string `json:"format"`
+	}
+
+	// NotOneOf is an error reason which indicates that a value is not any of
+	// the allowed values.
+	NotOneOf struct {
+		Field  string   `json:"field"`
+		Values []string `json:"values"`
+	}
+)
+
+// Class method returns ClassUserError.
+func (r Required) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Class method returns ClassUserError.
+func (r TooShort) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Class method returns ClassUserError.
+func (r TooLong) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Class method returns ClassUserError.
+func (r TooSmall) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Class method returns ClassUserError.
+func (r TooLarge) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Class method returns ClassUserError.
+func (r InvalidFormat) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Class method returns ClassUserError.
+func (r NotOneOf) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+func init() {
+	reasonederror.SetReasonMessage(Required{}, "{{.Field}} is required")
+	reasonederror.SetReasonMessage(TooShort{},
+		"{{.Field}} must be at least {{.Min}} in length")
+	reasonederror.SetReasonMessage(TooLong{},
+		"{{.Field}} must be at most {{.Max}} in length")
+	reasonederror.SetReasonMessage(TooSmall{},
+		"{{.Field}} must be greater than or equal to {{.Min}}")
+	reasonederror.SetReasonMessage(TooLarge{},
+		"{{.Field}} must be less than or equal to {{.Max}}")
+	reasonederror.SetReasonMessage(InvalidFormat{},
+		"{{.Field}} must be a valid {{.Format}}")
+	reasonederror.SetReasonMessage(NotOneOf{},
+		`{{.Field}} must be one of {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v}}{{end}}`)
+}
+
+// builtinRule is a function type of the rules provided by this package,
+// which returns an Err of which reason is InvalidRule as the second result if
+// the parameter or the field type is not applicable.
+type builtinRule func(field string, value reflect.Value, param string) (interface{}, reasonederror.Err)
+
+var builtinRules = map[string]builtinRule{
+	"min":   checkMin,
+	"max":   checkMax,
+	"email": checkEmail,
+	"url":   checkURL,
+	"oneof": checkOneOf,
+}
+
+func checkMin(field string, v reflect.Value, param string) (interface{}, reasonederror.Err) {
+	if n, ok := length(v); ok {
+		min, e := strconv.Atoi(param)
+		if e != nil {
+			return nil, invalidRule(field, "min", param, e)
+		}
+		if n < min {
+			return TooShort{Field: field, Min: min, Actual: n}, reasonederror.Ok()
+		}
+		return nil, reasonederror.Ok()
+	}
+
+	if x, ok := number(v); ok {
+		min, e := strconv.ParseFloat(param, 64)
+		if e != nil {
+			return nil, invalidRule(field, "min", param, e)
+		}
+		if x < min {
+			return TooSmall{Field: field, Min: min, Actual: x}, reasonederror.Ok()
+		}
+		return nil, reasonederror.Ok()
+	}
+
+	return nil, invalidRule(field, "min", param)
+}
+
+func checkMax(field string, v reflect.Value, param string) (interface{}, reasonederror.Err) {
+	if n, ok := length(v); ok {
+		max, e := strconv.Atoi(param)
+		if e != nil {
+			return nil, invalidRule(field, "max", param, e)
+		}
+		if n > max {
+			return TooLong{Field: field, Max: max, Actual: n}, reasonederror.Ok()
+		}
+		return nil, reasonederror.Ok()
+	}
+
+	if x, ok := number(v); ok {
+		max, e := strconv.ParseFloat(param, 64)
+		if e != nil {
+			return nil, invalidRule(field, "max", param, e)
+		}
+		if x > max {
+			return TooLarge{Field: field, Max: max, Actual: x}, reasonederror.Ok()
+		}
+		return nil, reasonederror.Ok()
+	}
+
+	return nil, invalidRule(field, "max", param)
+}
+
+func checkEmail(field string, v reflect.Value, param string) (interface{}, reasonederror.Err) {
+	if v.Kind() != reflect.String {
+		return nil, invalidRule(field, "email", param)
+	}
+
+	s := v.String()
+	addr, e := mail.ParseAddress(s)
+	if e != nil || addr.Name != "" || addr.Address != s {
+		return InvalidFormat{Field: field, Format: "email"}, reasonederror.Ok()
+	}
+	return nil, reasonederror.Ok()
+}
+
+func checkURL(field string, v reflect.Value, param string) (interface{}, reasonederror.Err) {
+	if v.Kind() != reflect.String {
+		return nil, invalidRule(field, "url", param)
+	}
+
+	u, e := url.Parse(v.String())
+	if e != nil || u.Scheme == "" || u.Host == "" {
+		return InvalidFormat{Field: field, Format: "url"}, reasonederror.Ok()
+	}
+	return nil, reasonederror.Ok()
+}
+
+func checkOneOf(field string, v reflect.Value, param string) (interface{}, reasonederror.Err) {
+	var s string
+	switch v.Kind() {
+	case reflect.String:
+		s = v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(v.Uint(), 10)
+	default:
+		return nil, invalidRule(field, "oneof", param)
+	}
+
+	values := strings.Fields(param)
+	for _, x := range values {
+		if x == s {
+			return nil, reasonederror.Ok()
+		}
+	}
+	return NotOneOf{Field: field, Values: values}, reasonederror.Ok()
+}
+
+func length(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+func number(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func invalidRule(field, rule, param string, cause ...error) reasonederror.Err {
+	return reasonederror.NewErr(InvalidRule{
+		Field: field,
+		Rule:  rule,
+		Param: param,
+	}, cause...)
+}
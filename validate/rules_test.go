@@ -0,0 +1,102 @@
+package validate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/validate"
+)
+
+func TestRules_minMax(t *testing.T) {
+	type Form struct {
+		Name   string            `validate:"min=2,max=4"`
+		Tags   []string          `validate:"min=2,max=3"`
+		Attrs  map[string]string `validate:"max=1"`
+		Score  float64           `validate:"min=0.5,max=1.5"`
+		Count  uint              `validate:"max=3"`
+		Offset int8              `validate:"min=-3"`
+	}
+
+	assert.True(t, validate.Struct(Form{
+		Name: "日本", Tags: []string{"a", "b"}, Attrs: map[string]string{"k": "v"},
+		Score: 1.5, Count: 3, Offset: -3,
+	}).IsOk())
+
+	err := validate.Struct(Form{
+		Name: "日本語です", Tags: []string{"a"}, Attrs: map[string]string{"a": "", "b": ""},
+		Score: 0.25, Count: 4, Offset: -4,
+	})
+	assert.Equal(t, reasonsOf(err), []interface{}{
+		validate.TooLong{Field: "Name", Max: 4, Actual: 5},
+		validate.TooShort{Field: "Tags", Min: 2, Actual: 1},
+		validate.TooLong{Field: "Attrs", Max: 1, Actual: 2},
+		validate.TooSmall{Field: "Score", Min: 0.5, Actual: 0.25},
+		validate.TooLarge{Field: "Count", Max: 3, Actual: 4},
+		validate.TooSmall{Field: "Offset", Min: -3, Actual: -4},
+	})
+}
+
+func TestRules_emailAndURL(t *testing.T) {
+	type Form struct {
+		Email    string `validate:"email"`
+		Homepage string `validate:"url"`
+	}
+
+	valids := []Form{
+		{Email: "a@example.com", Homepage: "https://example.com/a?b=c"},
+		{Email: "a.b+c@sub.example.org", Homepage: "http://localhost:8080"},
+	}
+	for _, f := range valids {
+		assert.True(t, validate.Struct(f).IsOk(), f)
+	}
+
+	invalids := []Form{
+		{Email: "alice", Homepage: "example.com"},
+		{Email: "Alice <a@example.com>", Homepage: "/path/only"},
+		{Email: "a@", Homepage: "http://"},
+	}
+	for _, f := range invalids {
+		assert.Equal(t, reasonsOf(validate.Struct(f)), []interface{}{
+			validate.InvalidFormat{Field: "Email", Format: "email"},
+			validate.InvalidFormat{Field: "Homepage", Format: "url"},
+		}, f)
+	}
+}
+
+func TestRules_oneof(t *testing.T) {
+	type Form struct {
+		Color string `validate:"oneof=red green"`
+		Level int    `validate:"oneof=1 2 3"`
+		Flags uint8  `validate:"oneof=4"`
+	}
+
+	assert.True(t, validate.Struct(Form{Color: "red", Level: 2, Flags: 4}).IsOk())
+
+	err := validate.Struct(Form{Color: "blue", Level: 5, Flags: 1})
+	assert.Equal(t, reasonsOf(err), []interface{}{
+		validate.NotOneOf{Field: "Color", Values: []string{"red", "green"}},
+		validate.NotOneOf{Field: "Level", Values: []string{"1", "2", "3"}},
+		validate.NotOneOf{Field: "Flags", Values: []string{"4"}},
+	})
+}
+
+func TestRules_message(t *testing.T) {
+	tests := []struct {
+		reason   interface{}
+		expected string
+	}{
+		{validate.Required{Field: "name"}, "name is required"},
+		{validate.TooShort{Field: "name", Min: 3, Actual: 1}, "name must be at least 3 in length"},
+		{validate.TooLong{Field: "name", Max: 3, Actual: 5}, "name must be at most 3 in length"},
+		{validate.TooSmall{Field: "age", Min: 18, Actual: 3}, "age must be greater than or equal to 18"},
+		{validate.TooLarge{Field: "age", Max: 1.5, Actual: 3}, "age must be less than or equal to 1.5"},
+		{validate.InvalidFormat{Field: "email", Format: "email"}, "email must be a valid email"},
+		{validate.NotOneOf{Field: "color", Values: []string{"red", "green"}}, "color must be one of red, green"},
+	}
+	for _, test := range tests {
+		err := reasonederror.NewErr(test.reason)
+		assert.Equal(t, err.Message(), test.expected)
+		assert.True(t, err.IsUserError(), err.ReasonName())
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package validate provides a validator of struct fields with validate tags,
+// which reports the failures as reasonederror.Err(s).
+//
+// The rules of a field are written in its validate tag separated by commas,
+// and a rule can take a parameter after "=":
+//
+//	type User struct {
+//		Name  string `json:"name" validate:"required,min=3,max=20"`
+//		Email string `json:"email" validate:"required,email"`
+//		Role  string `json:"role" validate:"oneof=admin member"`
+//	}
+//
+// The built-in rules are as follows:
+//
+//   - required: the value must not be a zero value, nil or empty.
+//   - min=N: the length of a string, a slice, an array or a map must be N or
+//     more, or a number must be N or more.
+//   - max=N: the length of a string, a slice, an array or a map must be N or
+//     less, or a number must be N or less.
+//   - email: the string must be an e-mail address.
+//   - url: the string must be an absolute URL.
+//   - oneof=A B C: the string or the integer must be any of the values.
+//
+// The rules other than required are not applied to an empty value.
+// Custom rules can be added with RegisterRule function.
+package validate
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sttk/reasonederror"
+)
+
+type /* error reasons */ (
+	// InvalidFields is an error reason which indicates that some fields of a
+	// struct are invalid.
+	// The cause is a reasonederror.Errs which contains an Err for each
+	// failure.
+	InvalidFields struct {
+		Count int
+	}
+
+	// InvalidRule is an error reason which indicates that a rule in a validate
+	// tag is unknown, or its parameter or the field type is not applicable.
+	InvalidRule struct {
+		Field string
+		Rule  string
+		Param string
+	}
+
+	// NotStruct is an error reason which indicates that a value passed to
+	// Struct function is neither a struct nor a pointer to a struct.
+	NotStruct struct {
+		Type string
+	}
+)
+
+// Class method returns ClassUserError.
+func (r InvalidFields) Class() reasonederror.Class { return reasonederror.ClassUserError }
+
+// Rule is a function type which checks a field value with the parameter
+// written after "=" in a validate tag.
+// field is the dotted path of the field, and value is the field value of
+// which pointers are dereferenced.
+// If the value is invalid, a Rule returns an error reason, otherwise nil.
+type Rule func(field string, value reflect.Value, param string) (reason interface{})
+
+var (
+	customRules = make(map[string]Rule)
+	ruleLock    = sync.RWMutex{}
+)
+
+// RegisterRule is a function which registers a custom rule with the specified
+// name, which can be used in validate tags.
+// A custom rule takes precedence over a built-in rule with the same name.
+func RegisterRule(name string, rule Rule) {
+	ruleLock.Lock()
+	defer ruleLock.Unlock()
+
+	customRules[name] = rule
+}
+
+// Struct is a function which validates the fields of the specified struct
+// with their validate tags, including the fields of nested structs and of
+// structs in slices and arrays.
+//
+// If some fields are invalid, this function returns an Err of which reason is
+// InvalidFields and cause is a reasonederror.Errs which contains an Err for
+// each failure.
+// The Field of each reason is the dotted path of the field, like
+// "address.city" or "items[0].name", in which the name of a field is its
+// name in the json tag if any, and the fields of an embedded struct without
+// a json name are regarded as the fields of the outer struct.
+// If a validate tag is malformed, this function returns an Err of which reason
+// is InvalidRule, and if the value is not a struct, this function returns an
+// Err of which reason is NotStruct.
+// If all fields are valid, this function returns Ok.
+func Struct(s interface{}) reasonederror.Err {
+	v := reflect.ValueOf(s)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		t := "nil"
+		if s != nil {
+			t = reflect.TypeOf(s).String()
+		}
+		return reasonederror.NewErr(NotStruct{Type: t})
+	}
+
+	var errs reasonederror.Errs
+	if err := validateStruct(v, "", &errs); err.IsNotOk() {
+		return err
+	}
+
+	if len(errs) == 0 {
+		return reasonederror.Ok()
+	}
+	return reasonederror.NewErr(InvalidFields{Count: len(errs)}, errs)
+}
+
+func validateStruct(v reflect.Value, path string, errs *reasonederror.Errs) reasonederror.Err {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		field := joinPath(path, fieldName(sf))
+		if sf.Anonymous && !hasJSONName(sf) {
+			field = path
+		}
+		if err := validateField(v.Field(i), field, sf.Tag.Get("validate"), errs); err.IsNotOk() {
+			return err
+		}
+	}
+	return reasonederror.Ok()
+}
+
+func validateField(v reflect.Value, field, tag string, errs *reasonederror.Errs) reasonederror.Err {
+	rules := parseTag(tag)
+
+	empty := isEmpty(v)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			break
+		}
+		v = v.Elem()
+	}
+
+	for _, r := range rules {
+		if r.name == "required" {
+			if empty {
+				*errs = append(*errs, reasonederror.NewErr(Required{Field: field}))
+				break
+			}
+			continue
+		}
+		if empty {
+			continue
+		}
+
+		reason, err := applyRule(r, field, v)
+		if err.IsNotOk() {
+			return err
+		}
+		if reason != nil {
+			*errs = append(*errs, reasonederror.NewErr(reason))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return validateStruct(v, field, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct {
+				break
+			}
+			p := field + "[" + strconv.Itoa(i) + "]"
+			if err := validateStruct(elem, p, errs); err.IsNotOk() {
+				return err
+			}
+		}
+	}
+
+	return reasonederror.Ok()
+}
+
+func applyRule(r rule, field string, v reflect.Value) (interface{}, reasonederror.Err) {
+	ruleLock.RLock()
+	custom, ok := customRules[r.name]
+	ruleLock.RUnlock()
+
+	if ok {
+		return custom(field, v, r.param), reasonederror.Ok()
+	}
+
+	builtin, ok := builtinRules[r.name]
+	if !ok {
+		return nil, invalidRule(field, r.name, r.param)
+	}
+	return builtin(field, v, r.param)
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func parseTag(tag string) []rule {
+	if tag == "" || tag == "-" {
+		return nil
+	}
+
+	var rules []rule
+	for _, s := range strings.Split(tag, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		name, param := s, ""
+		if i := strings.IndexByte(s, '='); i >= 0 {
+			name, param = s[:i], s[i+1:]
+		}
+		rules = append(rules, rule{name: name, param: param})
+	}
+	return rules
+}
+
+func fieldName(sf reflect.StructField) string {
+	if hasJSONName(sf) {
+		return strings.Split(sf.Tag.Get("json"), ",")[0]
+	}
+	return sf.Name
+}
+
+func hasJSONName(sf reflect.StructField) bool {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	return name != "" && name != "-"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
@@ -0,0 +1,164 @@
+package validate_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/validate"
+)
+
+type /* error reasons */ (
+	NotUpperCase struct {
+		Field string
+	}
+)
+
+type Address struct {
+	City    string `json:"city" validate:"required"`
+	Zipcode string `json:"zipcode" validate:"min=5,max=5"`
+}
+
+type Item struct {
+	Name     string `json:"name" validate:"required"`
+	Quantity int    `json:"quantity" validate:"min=1,max=99"`
+}
+
+type Timestamps struct {
+	CreatedBy string `validate:"required"`
+}
+
+type Order struct {
+	Timestamps
+	ID       string   `json:"id" validate:"required,upper"`
+	Email    string   `json:"email" validate:"required,email"`
+	Address  Address  `json:"address"`
+	Billing  *Address `json:"billing"`
+	Items    []Item   `json:"items" validate:"required,max=3"`
+	Status   string   `json:"status" validate:"oneof=new paid shipped"`
+	Note     *string  `json:"note" validate:"max=10"`
+	internal string   `validate:"required"`
+}
+
+func init() {
+	validate.RegisterRule("upper", func(field string, v reflect.Value, param string) interface{} {
+		if v.String() != strings.ToUpper(v.String()) {
+			return NotUpperCase{Field: field}
+		}
+		return nil
+	})
+}
+
+func reasonsOf(err reasonederror.Err) []interface{} {
+	var errs reasonederror.Errs
+	if !errors.As(err.Cause(), &errs) {
+		return nil
+	}
+	a := make([]interface{}, len(errs))
+	for i, e := range errs {
+		a[i] = e.Reason()
+	}
+	return a
+}
+
+func TestStruct_valid(t *testing.T) {
+	note := "fragile"
+	order := Order{
+		Timestamps: Timestamps{CreatedBy: "alice"},
+		ID:         "A001",
+		Email:      "alice@example.com",
+		Address:    Address{City: "Tokyo", Zipcode: "10000"},
+		Items:      []Item{{Name: "pen", Quantity: 3}},
+		Status:     "paid",
+		Note:       &note,
+	}
+	assert.True(t, validate.Struct(order).IsOk())
+	assert.True(t, validate.Struct(&order).IsOk())
+}
+
+func TestStruct_invalid(t *testing.T) {
+	note := "handle with care"
+	order := Order{
+		ID:      "a001",
+		Email:   "alice",
+		Address: Address{Zipcode: "123"},
+		Billing: &Address{City: "Osaka", Zipcode: "1234567"},
+		Items:   []Item{{Name: "pen", Quantity: 1}, {Quantity: 100}},
+		Status:  "lost",
+		Note:    &note,
+	}
+
+	err := validate.Struct(&order)
+	switch r := err.Reason().(type) {
+	case validate.InvalidFields:
+		assert.Equal(t, r.Count, 10)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.True(t, err.IsUserError())
+
+	assert.Equal(t, reasonsOf(err), []interface{}{
+		validate.Required{Field: "CreatedBy"},
+		NotUpperCase{Field: "id"},
+		validate.InvalidFormat{Field: "email", Format: "email"},
+		validate.Required{Field: "address.city"},
+		validate.TooShort{Field: "address.zipcode", Min: 5, Actual: 3},
+		validate.TooLong{Field: "billing.zipcode", Max: 5, Actual: 7},
+		validate.Required{Field: "items[1].name"},
+		validate.TooLarge{Field: "items[1].quantity", Max: 99, Actual: 100},
+		validate.NotOneOf{Field: "status", Values: []string{"new", "paid", "shipped"}},
+		validate.TooLong{Field: "note", Max: 10, Actual: 16},
+	})
+}
+
+func TestStruct_emptyValuesSkipRules(t *testing.T) {
+	type Form struct {
+		Nickname string   `validate:"min=3"`
+		Homepage string   `validate:"url"`
+		Tags     []string `validate:"min=1"`
+		Age      *int     `validate:"min=18"`
+		Name     *string  `validate:"required"`
+	}
+
+	err := validate.Struct(Form{})
+	assert.Equal(t, reasonsOf(err), []interface{}{
+		validate.Required{Field: "Name"},
+	})
+}
+
+func TestStruct_invalidRule(t *testing.T) {
+	type UnknownRule struct {
+		Name string `validate:"required,unknown=1"`
+	}
+	err := validate.Struct(UnknownRule{Name: "a"})
+	assert.Equal(t, err.Reason(), validate.InvalidRule{Field: "Name", Rule: "unknown", Param: "1"})
+	assert.False(t, err.IsUserError())
+
+	type BadParam struct {
+		Name string `validate:"min=abc"`
+	}
+	err = validate.Struct(BadParam{Name: "a"})
+	assert.Equal(t, err.Reason(), validate.InvalidRule{Field: "Name", Rule: "min", Param: "abc"})
+	assert.NotNil(t, err.Cause())
+
+	type BadType struct {
+		Flag bool `validate:"email"`
+	}
+	err = validate.Struct(BadType{Flag: true})
+	assert.Equal(t, err.Reason(), validate.InvalidRule{Field: "Flag", Rule: "email", Param: ""})
+}
+
+func TestStruct_notStruct(t *testing.T) {
+	err := validate.Struct("abc")
+	assert.Equal(t, err.Reason(), validate.NotStruct{Type: "string"})
+
+	err = validate.Struct(nil)
+	assert.Equal(t, err.Reason(), validate.NotStruct{Type: "nil"})
+
+	var order *Order
+	err = validate.Struct(order)
+	assert.Equal(t, err.Reason(), validate.NotStruct{Type: "*validate_test.Order"})
+}
@@ -0,0 +1,340 @@
+// Copyright (C) 2021-2023 Takayuki Sato. All Rights Reserved.
+// This program is free software under MIT License.
+// See the file LICENSE in this distribution for more details.
+
+// Package webhook provides an Err notification handler which posts batches of
+// notifications as JSON to a URL, like an alert receiver.
+//
+//	hook := webhook.NewWebhook(webhook.Options{
+//	    URL:         "https://alert.example.com/hooks/errs",
+//	    Secret:      []byte(os.Getenv("WEBHOOK_SECRET")),
+//	    MinSeverity: reasonederror.SeverityError,
+//	})
+//	defer hook.Close()
+//
+//	reasonederror.AddAsyncErrHandler(hook.Handle)
+//	reasonederror.FixErrCfgs()
+//
+// The body of a request is a JSON object like {"errs": [...]}, of which
+// elements are jsonlines.Record(s).
+// If a secret is configured, the request has the header "X-Signature-256"
+// of which value is "sha256=" followed by the hex-encoded HMAC-SHA256 of the
+// body.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/jsonlines"
+)
+
+type /* error reasons */ (
+	// FailToSend is an error reason which indicates that a Webhook failed to
+	// send some batches.
+	// The cause is the first error which occurred.
+	FailToSend struct {
+		URL     string
+		Batches int
+	}
+)
+
+// SignatureHeader is the name of the HTTP header which has the signature of
+// a request body.
+const SignatureHeader = "X-Signature-256"
+
+const (
+	defaultBatchSize       = 100
+	defaultFlushInterval   = time.Second
+	defaultBufferSize      = 1000
+	defaultMaxAttempts     = 3
+	defaultInitialInterval = 500 * time.Millisecond
+	defaultTimeout         = 10 * time.Second
+)
+
+// Options is a struct which configures a Webhook.
+type Options struct {
+	// URL is the URL to which batches are posted.
+	URL string
+
+	// Secret is the key of HMAC-SHA256 signatures of request bodies.
+	// If this is empty, requests are not signed.
+	Secret []byte
+
+	// MinSeverity is the minimum severity of Err(s) to be sent.
+	// Because the zero value is SeverityError, Err(s) of which severities are
+	// lower than SeverityError are not sent by default.
+	MinSeverity reasonederror.Severity
+
+	// BatchSize is the maximum number of notifications in a request.
+	// If this is zero or less, 100 is used.
+	BatchSize int
+
+	// FlushInterval is the maximum time to wait before sending a batch which
+	// is not full.
+	// If this is zero or less, 1 second is used.
+	FlushInterval time.Duration
+
+	// BufferSize is the maximum number of notifications which wait to be
+	// sent. Notifications over this are dropped.
+	// If this is zero or less, 1000 is used.
+	BufferSize int
+
+	// MaxAttempts is the maximum number of attempts to send a batch.
+	// If this is zero or less, 3 is used.
+	MaxAttempts int
+
+	// InitialInterval is the wait time before the second attempt, which is
+	// doubled at each attempt.
+	// If this is zero or less, 500 milliseconds is used.
+	InitialInterval time.Duration
+
+	// Timeout is the time limit of a request.
+	// If this is zero or less, 10 seconds is used.
+	Timeout time.Duration
+
+	// Client is the HTTP client to send requests.
+	// If this is nil, http.DefaultClient is used.
+	Client *http.Client
+
+	// OnDrop is the function which is called when a notification is dropped
+	// because the buffer is full or the batch could not be sent.
+	// This is useful to count drops, like with metrics.Metrics.Dropped.
+	OnDrop func()
+
+	// OnError is the function which is called when an attempt to send a batch
+	// fails.
+	OnError func(error)
+}
+
+// Webhook is a struct which posts batches of Err notifications.
+// Handle method never blocks, and the notifications are sent by a goroutine.
+type Webhook struct {
+	opts   Options
+	queue  chan jsonlines.Record
+	done   chan struct{}
+	closed chan struct{}
+	once   sync.Once
+
+	mutex    sync.Mutex
+	failed   int
+	firstErr error
+}
+
+// NewWebhook is a function which creates a Webhook and starts its goroutine.
+func NewWebhook(opts Options) *Webhook {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = defaultBufferSize
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.InitialInterval <= 0 {
+		opts.InitialInterval = defaultInitialInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	w := &Webhook{
+		opts:   opts,
+		queue:  make(chan jsonlines.Record, opts.BufferSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Handle method puts a notification of the specified Err into the buffer.
+// If the severity of the Err is lower than MinSeverity, it is ignored, and if
+// the buffer is full or this Webhook is closed, it is dropped.
+// This method is used as an Err notification handler.
+func (w *Webhook) Handle(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+	if err.Severity() < w.opts.MinSeverity {
+		return
+	}
+
+	select {
+	case <-w.done:
+		w.drop(1)
+		return
+	default:
+	}
+
+	select {
+	case w.queue <- jsonlines.NewRecord(err, occ):
+	default:
+		w.drop(1)
+	}
+}
+
+// Close method sends the buffered notifications and stops this Webhook.
+// If some batches could not be sent, this method returns an Err of which
+// reason is FailToSend.
+func (w *Webhook) Close() reasonederror.Err {
+	w.once.Do(func() { close(w.done) })
+	<-w.closed
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.failed > 0 {
+		return reasonederror.NewErr(FailToSend{
+			URL:     w.opts.URL,
+			Batches: w.failed,
+		}, w.firstErr)
+	}
+	return reasonederror.Ok()
+}
+
+func (w *Webhook) run() {
+	defer close(w.closed)
+
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]jsonlines.Record, 0, w.opts.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			w.send(batch)
+			batch = make([]jsonlines.Record, 0, w.opts.BatchSize)
+		}
+	}
+
+	for {
+		select {
+		case rec := <-w.queue:
+			batch = append(batch, rec)
+			if len(batch) >= w.opts.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-w.done:
+			for {
+				select {
+				case rec := <-w.queue:
+					batch = append(batch, rec)
+					if len(batch) >= w.opts.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *Webhook) send(batch []jsonlines.Record) {
+	body, e := json.Marshal(struct {
+		Errs []jsonlines.Record `json:"errs"`
+	}{batch})
+	if e != nil {
+		w.fail(e, len(batch))
+		return
+	}
+
+	interval := w.opts.InitialInterval
+	for attempt := 1; ; attempt++ {
+		retryable, e := w.post(body)
+		if e == nil {
+			return
+		}
+		if w.opts.OnError != nil {
+			w.opts.OnError(e)
+		}
+		if !retryable || attempt >= w.opts.MaxAttempts {
+			w.fail(e, len(batch))
+			return
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-w.done:
+			// While closing, retries are not delayed.
+			timer.Stop()
+		}
+		interval *= 2
+	}
+}
+
+func (w *Webhook) post(body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.Timeout)
+	defer cancel()
+
+	req, e := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.URL, bytes.NewReader(body))
+	if e != nil {
+		return false, e
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.opts.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.opts.Secret, body))
+	}
+
+	resp, e := w.opts.Client.Do(req)
+	if e != nil {
+		return true, e
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	e = fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, e
+}
+
+func (w *Webhook) fail(e error, n int) {
+	w.mutex.Lock()
+	w.failed++
+	if w.firstErr == nil {
+		w.firstErr = e
+	}
+	w.mutex.Unlock()
+
+	w.drop(n)
+}
+
+func (w *Webhook) drop(n int) {
+	if w.opts.OnDrop != nil {
+		for i := 0; i < n; i++ {
+			w.opts.OnDrop()
+		}
+	}
+}
+
+// Sign is a function which returns the signature of the specified body with
+// the specified secret, which is the value of the header SignatureHeader.
+// A receiver can verify a request by comparing the header value with the
+// result of this function with hmac.Equal function.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,246 @@
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/sttk/reasonederror"
+	"github.com/sttk/reasonederror/jsonlines"
+	"github.com/sttk/reasonederror/webhook"
+)
+
+type /* error reasons */ (
+	FailToConnect struct {
+		Host string
+	}
+	CacheMissed struct{}
+)
+
+func (r CacheMissed) Severity() reasonederror.Severity {
+	return reasonederror.SeverityInfo
+}
+
+var lastOcc reasonederror.ErrOccasion
+
+func TestMain(m *testing.M) {
+	reasonederror.AddSyncErrHandler(func(err reasonederror.Err, occ reasonederror.ErrOccasion) {
+		lastOcc = occ
+	})
+	reasonederror.FixErrCfgs()
+
+	os.Exit(m.Run())
+}
+
+type receiver struct {
+	mutex    sync.Mutex
+	batches  [][]jsonlines.Record
+	bodies   [][]byte
+	headers  []http.Header
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+	if status != http.StatusOK {
+		return
+	}
+
+	var payload struct {
+		Errs []jsonlines.Record `json:"errs"`
+	}
+	json.Unmarshal(body, &payload)
+	r.batches = append(r.batches, payload.Errs)
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header)
+}
+
+func (r *receiver) sizes() []int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	a := make([]int, len(r.batches))
+	for i, b := range r.batches {
+		a[i] = len(b)
+	}
+	return a
+}
+
+func newErr(host string) reasonederror.Err {
+	return reasonederror.NewErr(FailToConnect{Host: host})
+}
+
+func TestWebhook_batchBySize(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	hook := webhook.NewWebhook(webhook.Options{
+		URL:           srv.URL,
+		Secret:        []byte("s3cr3t"),
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	for _, host := range []string{"a", "b", "c", "d", "e"} {
+		hook.Handle(newErr(host), lastOcc)
+	}
+	assert.True(t, hook.Close().IsOk())
+
+	assert.Equal(t, rcv.sizes(), []int{2, 2, 1})
+	assert.Equal(t, rcv.batches[0][0].Reason, "FailToConnect")
+	assert.Equal(t, string(rcv.batches[0][0].Situation["Host"]), `"a"`)
+	assert.Equal(t, string(rcv.batches[2][0].Situation["Host"]), `"e"`)
+
+	for i, h := range rcv.headers {
+		assert.Equal(t, h.Get("Content-Type"), "application/json")
+		sig := h.Get(webhook.SignatureHeader)
+		assert.True(t, hmac.Equal([]byte(sig), []byte(webhook.Sign([]byte("s3cr3t"), rcv.bodies[i]))))
+	}
+}
+
+func TestWebhook_batchByTime(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	hook := webhook.NewWebhook(webhook.Options{
+		URL:           srv.URL,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	defer hook.Close()
+
+	hook.Handle(newErr("a"), lastOcc)
+	hook.Handle(newErr("b"), lastOcc)
+
+	assert.Eventually(t, func() bool {
+		return len(rcv.sizes()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, rcv.sizes(), []int{2})
+	assert.Equal(t, rcv.headers[0].Get(webhook.SignatureHeader), "")
+}
+
+func TestWebhook_minSeverity(t *testing.T) {
+	rcv := &receiver{}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	hook := webhook.NewWebhook(webhook.Options{URL: srv.URL})
+	hook.Handle(reasonederror.NewErr(CacheMissed{}), lastOcc)
+	hook.Handle(newErr("a"), lastOcc)
+	assert.True(t, hook.Close().IsOk())
+	assert.Equal(t, rcv.sizes(), []int{1})
+	assert.Equal(t, rcv.batches[0][0].Reason, "FailToConnect")
+
+	rcv = &receiver{}
+	srv2 := httptest.NewServer(rcv)
+	defer srv2.Close()
+
+	hook = webhook.NewWebhook(webhook.Options{
+		URL:         srv2.URL,
+		MinSeverity: reasonederror.SeverityDebug,
+	})
+	hook.Handle(reasonederror.NewErr(CacheMissed{}), lastOcc)
+	assert.True(t, hook.Close().IsOk())
+	assert.Equal(t, rcv.sizes(), []int{1})
+}
+
+func TestWebhook_retry(t *testing.T) {
+	rcv := &receiver{statuses: []int{500, 429}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	var errs []error
+	hook := webhook.NewWebhook(webhook.Options{
+		URL:             srv.URL,
+		InitialInterval: time.Millisecond,
+		OnError:         func(e error) { errs = append(errs, e) },
+	})
+	hook.Handle(newErr("a"), lastOcc)
+	assert.True(t, hook.Close().IsOk())
+
+	assert.Equal(t, rcv.sizes(), []int{1})
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs[0].Error(), "webhook responded with status 500")
+}
+
+func TestWebhook_failToSend(t *testing.T) {
+	rcv := &receiver{statuses: []int{503, 503, 503, 400}}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	var dropped int32
+	hook := webhook.NewWebhook(webhook.Options{
+		URL:             srv.URL,
+		BatchSize:       2,
+		FlushInterval:   time.Hour,
+		InitialInterval: time.Millisecond,
+		OnDrop:          func() { atomic.AddInt32(&dropped, 1) },
+	})
+	hook.Handle(newErr("a"), lastOcc)
+	hook.Handle(newErr("b"), lastOcc)
+	hook.Handle(newErr("c"), lastOcc)
+
+	err := hook.Close()
+	switch r := err.Reason().(type) {
+	case webhook.FailToSend:
+		assert.Equal(t, r.URL, srv.URL)
+		assert.Equal(t, r.Batches, 2)
+	default:
+		assert.Fail(t, err.Error())
+	}
+	assert.Equal(t, err.Cause().Error(), "webhook responded with status 503")
+	assert.Equal(t, atomic.LoadInt32(&dropped), int32(3))
+	assert.Equal(t, len(rcv.sizes()), 0)
+}
+
+func TestWebhook_neverBlocks(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+
+	var dropped int32
+	hook := webhook.NewWebhook(webhook.Options{
+		URL:           srv.URL,
+		BatchSize:     1,
+		BufferSize:    2,
+		FlushInterval: time.Hour,
+		OnDrop:        func() { atomic.AddInt32(&dropped, 1) },
+	})
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		hook.Handle(newErr("a"), lastOcc)
+	}
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+
+	// At most 1 in sending, 1 taken by the goroutine and 2 in the buffer.
+	n := atomic.LoadInt32(&dropped)
+	assert.True(t, n >= 6, n)
+
+	close(release)
+	assert.True(t, hook.Close().IsOk())
+
+	hook.Handle(newErr("a"), lastOcc)
+	assert.Equal(t, atomic.LoadInt32(&dropped), n+1)
+}